@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/lucky7xz/drako/internal/cli"
@@ -19,17 +20,31 @@ import (
 
 func Run() {
 	glassrootMode := false
+	dryRun := false
 	isTuiMode := false
 
 	// CLI handling
 	// =======================================
-	// Check for TUI-specific flags (Glassroot)
+	// "drako run" executes a cell headlessly. It is handled here rather than in cli,
+	// because it needs core, which itself depends on cli.
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		os.Exit(RunCell(os.Args[2:]))
+	}
+	// Likewise "drako doctor", which checks profiles with core and the clipboard with ui.
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(Doctor(os.Args[2:]))
+	}
+
+	// Check for TUI-specific flags (Glassroot, Dry Run)
 	// If present, we short-circuit the CLI handler entirely.
 	for _, arg := range os.Args {
-		if arg == "--glassroot" {
+		switch arg {
+		case "--glassroot":
 			isTuiMode = true
 			glassrootMode = true
-			break
+		case "--dry-run":
+			isTuiMode = true
+			dryRun = true
 		}
 	}
 
@@ -70,6 +85,9 @@ func Run() {
 	defer f.Close()
 	log.SetOutput(f)
 
+	// Captured output of the last debug run, opened in the viewer when the TUI comes back
+	var lastOutput *core.HistoryEntry
+
 	// Start of TUI Loop
 	for {
 
@@ -77,7 +95,13 @@ func Run() {
 		// We initialize with the *current* directory which might have changed
 		// from manual Chdir or from internal logic
 
-		program := tea.NewProgram(ui.InitialModel(glassrootMode))
+		model := ui.InitialModel(glassrootMode)
+		model.DryRun = dryRun
+		if lastOutput != nil {
+			model = model.OpenOutput(*lastOutput)
+			lastOutput = nil
+		}
+		program := tea.NewProgram(model)
 
 		result, err := program.Run()
 		if err != nil {
@@ -95,9 +119,15 @@ func Run() {
 		if state.Quitting {
 			return
 		}
+		// The dry-run toggle survives the TUI being rebuilt after a run.
+		dryRun = state.DryRun
 
 		if state.Selected != "" {
-			core.RunCommand(state.Config, state.Selected)
+			started := time.Now()
+			core.RunCommandWith(state.RunConfig(), state.Selected, state.RunOptions())
+			if e, ok := core.LastCapture("", ""); ok && !e.Start.Before(started) {
+				lastOutput = &e
+			}
 
 			cmd := exec.Command("clear")
 			cmd.Stdout = os.Stdout
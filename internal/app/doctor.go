@@ -0,0 +1,87 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lucky7xz/drako/internal/config"
+	"github.com/lucky7xz/drako/internal/core"
+	"github.com/lucky7xz/drako/internal/ui"
+)
+
+func printDoctorUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: drako doctor [--json] [--strict]\n\n")
+	fmt.Fprintf(w, "Checks config.toml, the shell, clipboard, git, themes, the pivot file and every\n")
+	fmt.Fprintf(w, "equipped profile: its grid, declared assets and the binaries its commands call.\n\n")
+	fmt.Fprintf(w, "Flags:\n")
+	fmt.Fprintf(w, "  --json     Print the report as JSON\n")
+	fmt.Fprintf(w, "  --strict   Exit with 1 on warnings too\n")
+	fmt.Fprintf(w, "\nExits with 1 if a check failed, 64 on bad arguments.\n")
+}
+
+// Doctor implements `drako doctor` and returns its exit code.
+func Doctor(args []string) int {
+	asJSON, strict := false, false
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			asJSON = true
+		case "--strict":
+			strict = true
+		case "-h", "--help":
+			printDoctorUsage(os.Stdout)
+			return 0
+		default:
+			fmt.Fprintf(os.Stderr, "Unexpected argument: %s\n\n", arg)
+			printDoctorUsage(os.Stderr)
+			return runExitUsage
+		}
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not get config dir: %v\n", err)
+		return 1
+	}
+	if closeLog := setupRunLogging(); closeLog != nil {
+		defer closeLog()
+	}
+
+	report := core.Diagnose(configDir, ui.ClipboardBackend())
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		writeDoctorReport(os.Stdout, report)
+	}
+
+	if report.Failed > 0 || (strict && report.Warned > 0) {
+		return 1
+	}
+	return 0
+}
+
+// writeDoctorReport prints the checks grouped by scope, setup first, then a summary line.
+func writeDoctorReport(w io.Writer, report core.DoctorReport) {
+	marks := map[string]string{core.DoctorPass: "✔ pass", core.DoctorWarn: "⚠ warn", core.DoctorFail: "✘ fail"}
+	scope := ""
+	for _, c := range report.Checks {
+		if c.Scope != scope {
+			scope = c.Scope
+			if scope == core.DoctorSetup {
+				fmt.Fprintf(w, "Setup\n")
+			} else {
+				fmt.Fprintf(w, "\nProfile %s\n", scope)
+			}
+		}
+		fmt.Fprintf(w, "  %s  %-12s %s\n", marks[c.Status], c.Name, c.Detail)
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n", report.Passed, report.Warned, report.Failed)
+}
@@ -0,0 +1,287 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/lucky7xz/drako/internal/config"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+// Exit codes of `drako run` when the cell itself did not decide one. They follow sysexits.h,
+// away from the 126/127/128+n that shells use, but a command may still exit with one of them
+// itself; --json tells the cases apart.
+const (
+	runExitFailed     = 1  // The command failed without an exit code (e.g. killed by a signal)
+	runExitUsage      = 64 // Bad arguments (EX_USAGE)
+	runExitNotFound   = 66 // No such cell or item in the profile (EX_NOINPUT)
+	runExitNotStarted = 69 // The command could not be started, needs --yes or its requires is unmet (EX_UNAVAILABLE)
+	runExitTimedOut   = 75 // The command hit its timeout (EX_TEMPFAIL)
+	runExitBadProfile = 78 // Unknown or broken profile (EX_CONFIG)
+)
+
+// runRequest is a parsed `drako run` invocation.
+type runRequest struct {
+	profile string
+	cell    string
+	item    string
+	params  map[string]string
+	host    string
+	json    bool
+	yes     bool
+}
+
+func printRunUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: drako run <profile> <cell> [item] [flags]\n\n")
+	fmt.Fprintf(w, "Runs a cell without the TUI and exits with the command's exit code.\n\n")
+	fmt.Fprintf(w, "Flags:\n")
+	fmt.Fprintf(w, "  --param name=value   Value for a declared parameter (repeatable)\n")
+	fmt.Fprintf(w, "  --host <host>        Target host when the profile declares several\n")
+	fmt.Fprintf(w, "  --yes                Run cells that ask for confirmation\n")
+	fmt.Fprintf(w, "  --json               Print a result record on stdout (command output goes to stderr)\n")
+	fmt.Fprintf(w, "\nExit codes: the command's own, or 64 usage, 66 cell not found, 69 not started,\n")
+	fmt.Fprintf(w, "75 timed out, 78 unknown profile. A command can exit with these too; --json tells them apart.\n")
+}
+
+// parseRunArgs reads the arguments after "run". Flags may appear anywhere.
+func parseRunArgs(args []string) (runRequest, error) {
+	req := runRequest{params: map[string]string{}}
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--json":
+			req.json = true
+		case "--yes", "-y":
+			req.yes = true
+		case "--param", "-p", "--host":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return req, fmt.Errorf("%s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+			if name == "--host" {
+				req.host = value
+				continue
+			}
+			k, v, ok := strings.Cut(value, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return req, fmt.Errorf("%s expects name=value, got %q", name, value)
+			}
+			req.params[strings.TrimSpace(k)] = v
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return req, fmt.Errorf("unknown flag %s", arg)
+			}
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) < 2 || len(positional) > 3 {
+		return req, fmt.Errorf("expected <profile> <cell> [item]")
+	}
+	req.profile, req.cell = positional[0], positional[1]
+	if len(positional) == 3 {
+		req.item = positional[2]
+	}
+	return req, nil
+}
+
+// RunCell implements `drako run`: it executes one cell headlessly and returns the exit code.
+func RunCell(args []string) int {
+	for _, arg := range args {
+		if arg == "-h" || arg == "--help" {
+			printRunUsage(os.Stdout)
+			return 0
+		}
+	}
+	req, err := parseRunArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		printRunUsage(os.Stderr)
+		return runExitUsage
+	}
+
+	if closeLog := setupRunLogging(); closeLog != nil {
+		defer closeLog()
+	}
+
+	bundle := config.LoadConfig(&req.profile)
+	profile, err := findRunProfile(bundle, req.profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return runExitBadProfile
+	}
+	cfg := bundle.Config
+
+	parent, item, err := findRunCell(cfg, profile.Name, req.cell, req.item)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (profile %s)\n", err, profile.Name)
+		return runExitNotFound
+	}
+	// The resolved cell is passed on as is: item names need not be unique across dropdowns.
+	selected, parentName, spec := parent.Name, "", core.ResolveCommandSpec(parent, item)
+	if item != nil {
+		selected, parentName = item.Name, parent.Name
+	}
+	if unmet := core.CellUnmet(cfg, parent, item); unmet != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s is unavailable: %s\n", strings.TrimSpace(selected), unmet)
+		return runExitNotStarted
+	}
+	if spec.Command == "" && len(spec.Steps) == 0 {
+		var items []string
+		if item == nil {
+			for _, it := range parent.Items {
+				items = append(items, it.Name)
+			}
+		}
+		if len(items) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: %s is a dropdown, name one of its items: %s\n", strings.TrimSpace(selected), strings.Join(items, ", "))
+			return runExitUsage
+		}
+		fmt.Fprintf(os.Stderr, "Error: %s has no command configured\n", selected)
+		return runExitNotStarted
+	}
+	if level := core.EffectiveConfirm(spec, core.ConfirmNone); level != core.ConfirmNone && !req.yes {
+		fmt.Fprintf(os.Stderr, "Error: %s asks for confirmation; pass --yes to run it\n", selected)
+		return runExitNotStarted
+	}
+
+	_ = os.Setenv("DRAKO_PROFILE", profile.Name)
+	opts := core.RunOptions{Params: req.params, Profile: profile.Name, Host: req.host, Parent: parentName}
+	out := io.Writer(os.Stdout)
+	if req.json {
+		// Keep stdout for the result record.
+		out = os.Stderr
+	}
+
+	result, runErr := core.RunHeadless(cfg, selected, opts, out)
+	if runErr != nil {
+		result.ExitCode = -1
+		result.Error = runErr.Error()
+		if !req.json {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", runErr)
+		}
+	}
+	if req.json {
+		line, err := json.Marshal(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		} else {
+			fmt.Println(string(line))
+		}
+	}
+
+	switch {
+	case runErr != nil:
+		return runExitNotStarted
+	case result.TimedOut:
+		return runExitTimedOut
+	case result.ExitCode > 0:
+		return result.ExitCode
+	case !result.Succeeded():
+		return runExitFailed
+	}
+	return 0
+}
+
+// findRunProfile returns the equipped profile called name. LoadConfig falls back to factory
+// defaults for unknown profiles, which must not run anything here.
+func findRunProfile(bundle config.ConfigBundle, name string) (config.ProfileInfo, error) {
+	target := config.NormalizeProfileName(name)
+	for _, p := range bundle.Profiles {
+		if config.NormalizeProfileName(p.Name) == target {
+			return p, nil
+		}
+	}
+	for _, b := range bundle.Broken {
+		if config.NormalizeProfileName(b.Name) == target {
+			return config.ProfileInfo{}, fmt.Errorf("profile %s could not be loaded: %s", name, b.Err)
+		}
+	}
+	return config.ProfileInfo{}, fmt.Errorf("no equipped profile named %s", name)
+}
+
+// findRunCell resolves a grid cell, or one item of a dropdown cell. item is nil for a cell.
+// Items of a dynamic dropdown are generated with its items_command.
+func findRunCell(cfg config.Config, profile, cell, item string) (*config.Command, *config.CommandItem, error) {
+	for i := range cfg.Commands {
+		parent := &cfg.Commands[i]
+		if !cellNameMatches(parent.Name, cell) {
+			continue
+		}
+		if item == "" {
+			return parent, nil, nil
+		}
+		for j := range parent.Items {
+			if cellNameMatches(parent.Items[j].Name, item) {
+				return parent, &parent.Items[j], nil
+			}
+		}
+		if strings.TrimSpace(parent.ItemsCommand) != "" {
+			generated, err := core.GenerateItems(cfg, *parent, core.RunOptions{Profile: profile})
+			if err != nil {
+				return nil, nil, err
+			}
+			// Recorded on the cell so RunHeadless finds the item through it.
+			parent.Generated = generated
+			for j := range parent.Generated {
+				if cellNameMatches(parent.Generated[j].Name, item) {
+					return parent, &parent.Generated[j], nil
+				}
+			}
+		}
+		return nil, nil, fmt.Errorf("cell %s has no item %s", cell, item)
+	}
+	return nil, nil, fmt.Errorf("no cell named %s", cell)
+}
+
+// cellNameMatches compares a configured name with one typed on the command line. Besides an
+// exact match, case, emoji and decorations such as "⋮" are ignored, so "👀 Process Monitor"
+// can be run as "process monitor".
+func cellNameMatches(name, query string) bool {
+	if name == query {
+		return true
+	}
+	plain := func(s string) string {
+		s = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || strings.ContainsRune("-_./:", r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, s)
+		return strings.Join(strings.Fields(s), " ")
+	}
+	p := plain(query)
+	return p != "" && plain(name) == p
+}
+
+// setupRunLogging sends log output to drako.log, as in the TUI, so it does not mix with the command's.
+func setupRunLogging() func() {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		log.SetOutput(io.Discard)
+		return nil
+	}
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		log.SetOutput(io.Discard)
+		return nil
+	}
+	logPath := filepath.Join(configDir, "drako.log")
+	core.RotateLogIfNeeded(logPath, 1024*1024)
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		log.SetOutput(io.Discard)
+		return nil
+	}
+	log.SetOutput(f)
+	return func() { f.Close() }
+}
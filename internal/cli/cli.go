@@ -39,6 +39,9 @@ func HandleCLI(args []string) bool {
 	case "strip", "--strip":
 		HandleStripCommand(args)
 		return true
+	case "list", "--list":
+		HandleListCommand(args)
+		return true
 	case "open", "--open":
 		HandleOpenCLI(args)
 		return true
@@ -61,14 +64,21 @@ func HandleCLI(args []string) bool {
 func PrintUsage() {
 	fmt.Printf("Usage: drako <command> [arguments]\n\n")
 	fmt.Printf("Commands:\n")
+	fmt.Printf("  run <profile> <cell> [item]\n")
+	fmt.Printf("                 Run a cell without the TUI (see drako run --help)\n")
 	fmt.Printf("  summon <url>   Summon a profile from a URL\n")
 	fmt.Printf("  purge          Delete profiles or config\n")
 	fmt.Printf("  spec           Manage specs\n")
 	fmt.Printf("  stash          Stash current profile\n")
 	fmt.Printf("  strip          Strip comments from profiles\n")
+	fmt.Printf("  list <what>    List profiles, inventory, specs or commands (--json)\n")
 	fmt.Printf("  open <path>    Open a file or directory\n")
+	fmt.Printf("  doctor         Check the setup and every profile (--json for CI)\n")
 	fmt.Printf("  version        Show version information\n")
 	fmt.Printf("  help           Show this help message\n")
+	fmt.Printf("\nFlags:\n")
+	fmt.Printf("  --glassroot    Start the TUI in glassroot mode\n")
+	fmt.Printf("  --dry-run      Start the TUI in dry-run mode (show what cells would run)\n")
 }
 
 // HandleSummonCommand processes the 'drako summon <url>' command
@@ -168,7 +178,7 @@ func ExecutePurge(args []string) error {
 	} else if opts.TargetConfig {
 		confirmMsg = "⚠️  This will reset your Core Configuration (config.toml). Proceed?"
 	} else if opts.TargetLogs {
-		confirmMsg = "💀 This will PERMANENTLY DELETE your log files (history.log, drako.log). Proceed?"
+		confirmMsg = "💀 This will PERMANENTLY DELETE your log files (history.jsonl, drako.log, jobs/). Proceed?"
 	} else if len(opts.TargetProfiles) > 0 {
 		confirmMsg = fmt.Sprintf("⚠️  This will remove %d profile(s): %s. Proceed?", len(opts.TargetProfiles), strings.Join(opts.TargetProfiles, ", "))
 	} else {
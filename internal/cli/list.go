@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// ListedProfile is a profile file as reported by `drako list profiles|inventory`.
+type ListedProfile struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Commands int    `json:"commands"`
+	Error    string `json:"error,omitempty"` // Set for profiles that fail to parse or validate
+}
+
+// ListedSpec is a spec file as reported by `drako list specs`.
+type ListedSpec struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Profiles []string `json:"profiles"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// ListedCommand is a grid cell as reported by `drako list commands`.
+type ListedCommand struct {
+	Profile     string       `json:"profile"`
+	Name        string       `json:"name"`
+	Col         string       `json:"col"`
+	Row         int          `json:"row"`
+	Description string       `json:"description,omitempty"`
+	Command     string       `json:"command,omitempty"`
+	Steps       []string     `json:"steps,omitempty"`
+	Items       []ListedItem `json:"items,omitempty"`
+	ItemsCmd    string       `json:"items_command,omitempty"` // More items are generated by this command when the dropdown opens
+}
+
+// ListedItem is one entry of a dropdown cell.
+type ListedItem struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Command     string   `json:"command,omitempty"`
+	Steps       []string `json:"steps,omitempty"`
+}
+
+func printListUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: drako list <profiles|inventory|specs|commands> [--profile <name>] [--json]\n\n")
+	fmt.Fprintf(w, "  profiles    Equipped profiles, including broken ones and their errors\n")
+	fmt.Fprintf(w, "  inventory   Profiles stored in inventory/\n")
+	fmt.Fprintf(w, "  specs       Spec files and the profiles they list\n")
+	fmt.Fprintf(w, "  commands    Cells with their grid position, description and items\n")
+	fmt.Fprintf(w, "              (all equipped profiles, or only --profile)\n")
+	fmt.Fprintf(w, "\nPlain output is tab-separated, one record per line.\n")
+}
+
+// HandleListCommand processes 'drako list <what> [--profile X] [--json]'.
+func HandleListCommand(args []string) {
+	var what, profile string
+	asJSON := false
+	rest := args[2:]
+	for i := 0; i < len(rest); i++ {
+		name, value, hasValue := strings.Cut(rest[i], "=")
+		switch name {
+		case "--json":
+			asJSON = true
+		case "--profile", "-p":
+			if !hasValue {
+				if i+1 >= len(rest) {
+					fmt.Fprintf(os.Stderr, "--profile needs a value\n")
+					os.Exit(1)
+				}
+				i++
+				value = rest[i]
+			}
+			profile = value
+		case "-h", "--help":
+			printListUsage(os.Stdout)
+			os.Exit(0)
+		default:
+			if strings.HasPrefix(rest[i], "-") || what != "" {
+				fmt.Fprintf(os.Stderr, "Unexpected argument: %s\n\n", rest[i])
+				printListUsage(os.Stderr)
+				os.Exit(1)
+			}
+			what = rest[i]
+		}
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not get config dir: %v\n", err)
+		os.Exit(1)
+	}
+
+	var records any
+	switch what {
+	case "profiles":
+		records = ListProfiles(configDir)
+	case "inventory":
+		records = ListProfiles(filepath.Join(configDir, "inventory"))
+	case "specs":
+		records = ListSpecs(configDir)
+	case "commands":
+		records, err = ListCommands(configDir, profile)
+	default:
+		printListUsage(os.Stderr)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := WriteList(os.Stdout, records, asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ListProfiles returns the profile files in dir, valid and broken, sorted by name.
+func ListProfiles(dir string) []ListedProfile {
+	profiles, broken := config.DiscoverProfilesWithErrors(dir)
+	out := make([]ListedProfile, 0, len(profiles)+len(broken))
+	for _, p := range profiles {
+		out = append(out, ListedProfile{Name: p.Name, Path: p.Path, Commands: len(p.Profile.Commands)})
+	}
+	for _, b := range broken {
+		out = append(out, ListedProfile{Name: b.Name, Path: b.Path, Error: b.Err})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ListSpecs returns the spec files in the specs directory, sorted by name.
+func ListSpecs(configDir string) []ListedSpec {
+	specsDir := filepath.Join(configDir, "specs")
+	out := []ListedSpec{}
+	entries, err := os.ReadDir(specsDir)
+	if err != nil {
+		return out
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".spec.toml") {
+			continue
+		}
+		listed := ListedSpec{
+			Name: strings.TrimSuffix(entry.Name(), ".spec.toml"),
+			Path: filepath.Join(specsDir, entry.Name()),
+		}
+		var spec Spec
+		if _, err := toml.DecodeFile(listed.Path, &spec); err != nil {
+			listed.Error = err.Error()
+		}
+		listed.Profiles = spec.Profiles
+		out = append(out, listed)
+	}
+	return out
+}
+
+// ListCommands returns the cells of the named equipped profile, or of every equipped profile
+// when profile is empty.
+func ListCommands(configDir, profile string) ([]ListedCommand, error) {
+	profiles, broken := config.DiscoverProfilesWithErrors(configDir)
+	target := config.NormalizeProfileName(profile)
+	out := []ListedCommand{}
+	found := false
+	for _, p := range profiles {
+		if target != "" && config.NormalizeProfileName(p.Name) != target {
+			continue
+		}
+		found = true
+		for _, c := range p.Profile.Commands {
+			listed := ListedCommand{
+				Profile:     p.Name,
+				Name:        c.Name,
+				Col:         c.Col,
+				Row:         c.Row,
+				Description: c.Description,
+				Command:     c.Command,
+				Steps:       stepNames(c.Steps),
+				ItemsCmd:    c.ItemsCommand,
+			}
+			for _, it := range c.Items {
+				listed.Items = append(listed.Items, ListedItem{
+					Name:        it.Name,
+					Description: it.Description,
+					Command:     it.Command,
+					Steps:       stepNames(it.Steps),
+				})
+			}
+			out = append(out, listed)
+		}
+	}
+	if target != "" && !found {
+		for _, b := range broken {
+			if config.NormalizeProfileName(b.Name) == target {
+				return nil, fmt.Errorf("profile %s could not be loaded: %s", profile, b.Err)
+			}
+		}
+		return nil, fmt.Errorf("no equipped profile named %s", profile)
+	}
+	return out, nil
+}
+
+func stepNames(steps []config.CommandStep) []string {
+	var names []string
+	for i, s := range steps {
+		name := s.Name
+		if name == "" {
+			name = "step " + strconv.Itoa(i+1)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// WriteList prints records as indented JSON, or as tab-separated lines for shell tools.
+// Commands get one line per dropdown item, so each line maps to a `drako run` invocation.
+func WriteList(w io.Writer, records any, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(records)
+	}
+
+	clean := func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	var err error
+	switch list := records.(type) {
+	case []ListedProfile:
+		for _, p := range list {
+			if p.Error != "" {
+				_, err = fmt.Fprintf(w, "%s\t%s\tbroken: %s\n", p.Name, p.Path, clean(p.Error))
+			} else {
+				_, err = fmt.Fprintf(w, "%s\t%s\t%d commands\n", p.Name, p.Path, p.Commands)
+			}
+		}
+	case []ListedSpec:
+		for _, s := range list {
+			if s.Error != "" {
+				_, err = fmt.Fprintf(w, "%s\t%s\tbroken: %s\n", s.Name, s.Path, clean(s.Error))
+			} else {
+				_, err = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.Path, strings.Join(s.Profiles, ","))
+			}
+		}
+	case []ListedCommand:
+		for _, c := range list {
+			pos := fmt.Sprintf("%s%d", c.Col, c.Row)
+			if len(c.Items) == 0 {
+				_, err = fmt.Fprintf(w, "%s\t%s\t%s\t\t%s\n", c.Profile, pos, clean(c.Name), clean(c.Description))
+				continue
+			}
+			for _, it := range c.Items {
+				_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Profile, pos, clean(c.Name), clean(it.Name), clean(it.Description))
+			}
+		}
+	default:
+		return fmt.Errorf("cannot list %T", records)
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListCommandsAndProfiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ops.profile.toml", `
+x = 2
+y = 2
+[[commands]]
+name = "Deploy"
+command = "make deploy"
+col = "A"
+row = 0
+[[commands]]
+name = "Status ⋮"
+description = "Cluster status"
+col = "B"
+row = 1
+items = [
+  { name = "Pods", command = "kubectl get pods" },
+  { name = "Nodes", command = "kubectl get nodes" },
+]
+`)
+	write("broken.profile.toml", "x = [")
+	write("specs/work.spec.toml", `profiles = ["ops"]`)
+
+	profiles := ListProfiles(dir)
+	if len(profiles) != 2 || profiles[0].Name != "broken" || profiles[0].Error == "" || profiles[1].Commands != 2 {
+		t.Fatalf("unexpected profiles: %+v", profiles)
+	}
+
+	specs := ListSpecs(dir)
+	if len(specs) != 1 || specs[0].Name != "work" || strings.Join(specs[0].Profiles, ",") != "ops" {
+		t.Fatalf("unexpected specs: %+v", specs)
+	}
+
+	cmds, err := ListCommands(dir, "OPS")
+	if err != nil {
+		t.Fatalf("ListCommands failed: %v", err)
+	}
+	if len(cmds) != 2 || cmds[1].Col != "B" || cmds[1].Row != 1 || len(cmds[1].Items) != 2 {
+		t.Fatalf("unexpected commands: %+v", cmds)
+	}
+	if _, err := ListCommands(dir, "broken"); err == nil || !strings.Contains(err.Error(), "could not be loaded") {
+		t.Fatalf("expected the parse error of the broken profile, got %v", err)
+	}
+	if _, err := ListCommands(dir, "missing"); err == nil {
+		t.Fatal("expected an error for an unknown profile")
+	}
+
+	var plain bytes.Buffer
+	if err := WriteList(&plain, cmds, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "ops\tA0\tDeploy\t\t\n" +
+		"ops\tB1\tStatus ⋮\tPods\t\n" +
+		"ops\tB1\tStatus ⋮\tNodes\t\n"
+	if plain.String() != want {
+		t.Fatalf("unexpected plain output:\n%q\nwant:\n%q", plain.String(), want)
+	}
+
+	var out bytes.Buffer
+	if err := WriteList(&out, cmds, true); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []ListedCommand
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if decoded[1].Items[0].Command != "kubectl get pods" {
+		t.Fatalf("unexpected JSON round trip: %+v", decoded)
+	}
+}
@@ -14,7 +14,7 @@ type PurgeOptions struct {
 	DestroyEverything bool     // Nuke ~/.config/drako entirely
 	TargetProfiles    []string // Delete/Move specific profiles (e.g. "git", "core")
 	TargetConfig      bool     // Reset config.toml
-	TargetLogs        bool     // Purge logs (history.log and drako.log)
+	TargetLogs        bool     // Purge logs (history, drako.log and jobs/)
 }
 
 // PurgeConfig executes the purge operation based on the options.
@@ -50,7 +50,7 @@ func PurgeConfig(configDir string, opts PurgeOptions) error {
 	if opts.TargetLogs {
 		log.Printf("Purging Logs (Permanent Delete)")
 		logFiles := []string{
-			"history.log", "history.log.old",
+			"history.jsonl", "history.log", "history.log.old",
 			"drako.log", "drako.log.old",
 		}
 		for _, f := range logFiles {
@@ -65,6 +65,16 @@ func PurgeConfig(configDir string, opts PurgeOptions) error {
 				fmt.Printf("  💀 Deleted %s\n", f)
 			}
 		}
+
+		// Background job output lives in its own directory
+		jobsDir := filepath.Join(configDir, "jobs")
+		if _, err := os.Stat(jobsDir); err == nil {
+			if err := os.RemoveAll(jobsDir); err != nil {
+				log.Printf("Failed to delete job logs: %v", err)
+			} else {
+				fmt.Printf("  💀 Deleted jobs/\n")
+			}
+		}
 	}
 
 	// Case 3: Target Specific Profiles
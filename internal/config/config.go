@@ -80,6 +80,10 @@ func RescueConfig() Config {
 		Keys: InputConfig{
 			Explain:      "e",
 			Inventory:    "i",
+			Jobs:         "J",
+			History:      "H",
+			Output:       "O",
+			DryRun:       "D",
 			PathGridMode: "tab",
 			Lock:         "r",
 			ProfilePrev:  "o",
@@ -92,6 +96,7 @@ func RescueConfig() Config {
 				Description: "Resets config.toml to defaults.\n\n• Your old config.toml will be moved to trash/. Note that if the Core profile has been removed, this will reinitialize it too\n• Use this to fix syntax errors in config.toml.\n• Drako will exit after this operation.",
 				Row:         0,
 				Col:         "a", // Left
+				Confirm:     "name",
 			},
 			{
 				Name:        "Remove Core Profile",
@@ -99,6 +104,7 @@ func RescueConfig() Config {
 				Description: "Removes your core.profile.toml.\n\n• Use this if the core profile layout is broken.",
 				Row:         1,
 				Col:         "a", // Left below Reset Core
+				Confirm:     "yes",
 			},
 			{
 				Name:        "Remove Another Profile",
@@ -106,6 +112,7 @@ func RescueConfig() Config {
 				Description: "Select a profile to remove.\n\n• Useful if a specific profile is broken and crashing Drako.\n• The profile will be moved to trash/.",
 				Row:         2,
 				Col:         "a", // Left below Reset Core Profile
+				Confirm:     "yes",
 			},
 			{
 				Name:        "Edit Config",
@@ -173,6 +180,18 @@ func (c *Config) ApplyDefaults() {
 	if strings.TrimSpace(c.Keys.Inventory) == "" {
 		c.Keys.Inventory = defaults.Keys.Inventory
 	}
+	if strings.TrimSpace(c.Keys.Jobs) == "" {
+		c.Keys.Jobs = defaults.Keys.Jobs
+	}
+	if strings.TrimSpace(c.Keys.History) == "" {
+		c.Keys.History = defaults.Keys.History
+	}
+	if strings.TrimSpace(c.Keys.Output) == "" {
+		c.Keys.Output = defaults.Keys.Output
+	}
+	if strings.TrimSpace(c.Keys.DryRun) == "" {
+		c.Keys.DryRun = defaults.Keys.DryRun
+	}
 	if strings.TrimSpace(c.Keys.PathGridMode) == "" {
 		c.Keys.PathGridMode = defaults.Keys.PathGridMode
 	}
@@ -244,6 +263,9 @@ func BuildGrid(config Config) [][]string {
 		grid[i] = make([]string, config.X)
 	}
 	for _, cmd := range config.Commands {
+		if cmd.Unmet != "" && cmd.Requires != nil && cmd.Requires.Hide {
+			continue
+		}
 		row := cmd.Row
 		col, err := letterToColumn(cmd.Col)
 		if err != nil {
@@ -338,6 +360,12 @@ func DiscoverProfilesWithErrors(configDir string) ([]ProfileInfo, []ProfileParse
 			continue
 		}
 
+		if err := ValidatePlaceholders(profileFile.Commands); err != nil {
+			broken = append(broken, ProfileParseError{Name: profileName, Path: fullPath, Err: err.Error()})
+			continue
+		}
+
+		ResolveOSVariants(profileFile.Commands)
 		discoveredProfiles = append(discoveredProfiles, ProfileInfo{
 			Name:    profileName,
 			Path:    fullPath,
@@ -380,12 +408,55 @@ func ApplyProfileOverlay(base Config, profile ProfileFile) Config {
 	if profile.Shell != nil {
 		cfg.DefaultShell = *profile.Shell
 	}
+	// Environment: the profile blocklist adds to the global one, its whitelist further
+	// restricts what the global settings allow, and env values are set last.
+	if len(profile.EnvBlocklist) > 0 {
+		cfg.EnvBlocklist = append(append([]string{}, base.EnvBlocklist...), profile.EnvBlocklist...)
+	}
+	cfg.ProfileEnvWhitelist = nil
+	if len(profile.EnvWhitelist) > 0 {
+		cfg.ProfileEnvWhitelist = append([]string{}, profile.EnvWhitelist...)
+	}
+	cfg.Env = nil
+	if len(profile.Env) > 0 {
+		cfg.Env = make(map[string]string, len(profile.Env))
+		for k, v := range profile.Env {
+			cfg.Env[k] = v
+		}
+	}
+	cfg.Timeout = strings.TrimSpace(profile.Timeout)
+	cfg.Launcher = strings.TrimSpace(profile.Launcher)
+	cfg.Hosts = profileHosts(profile)
+	cfg.SSHOptions = nil
+	if len(profile.SSHOptions) > 0 {
+		cfg.SSHOptions = append([]string{}, profile.SSHOptions...)
+	}
+	cfg.Container = nil
+	if profile.Container != nil {
+		container := *profile.Container
+		cfg.Container = &container
+	}
 	// Commands are mandatory in ProfileFile basically
 	cfg.Commands = CopyCommands(profile.Commands)
 
 	return cfg
 }
 
+// profileHosts merges host and hosts into one list without blanks or duplicates.
+func profileHosts(profile ProfileFile) []string {
+	var hosts []string
+	seen := map[string]bool{}
+	for _, h := range append([]string{profile.Host}, profile.Hosts...) {
+		h = strings.TrimSpace(h)
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
 const pivotProfileFilename = "pivot.toml"
 
 func GetConfigDir() (string, error) {
@@ -548,6 +619,10 @@ func LoadConfig(profileOverride *string) ConfigBundle {
 					AutoLockEnabled:    settings.AutoLockEnabled,
 					EnvWhitelist:       settings.EnvWhitelist,
 					EnvBlocklist:       settings.EnvBlocklist,
+					GlassrootConfirm:   settings.GlassrootConfirm,
+					NotifyAfter:        settings.NotifyAfter,
+					NotifyMethod:       settings.NotifyMethod,
+					NotifyCommand:      settings.NotifyCommand,
 					Theme:              settings.Theme,
 					Keys:               settings.Keys,
 					Commands:           []Command{}, // Explicitly empty
@@ -728,6 +803,10 @@ func LoadConfig(profileOverride *string) ConfigBundle {
 			LockTimeoutMinutes: base.LockTimeoutMinutes,
 			EnvWhitelist:       base.EnvWhitelist,
 			EnvBlocklist:       base.EnvBlocklist,
+			GlassrootConfirm:   base.GlassrootConfirm,
+			NotifyAfter:        base.NotifyAfter,
+			NotifyMethod:       base.NotifyMethod,
+			NotifyCommand:      base.NotifyCommand,
 			Theme:              base.Theme,
 			Keys:               base.Keys,
 		},
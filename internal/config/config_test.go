@@ -3,8 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/BurntSushi/toml"
 )
 
 // TestApplyDefaults ensures that a zero-value Config gets populated with safe defaults.
@@ -56,6 +59,101 @@ func TestApplyProfileOverlay_PreservesBase(t *testing.T) {
 	}
 }
 
+// TestApplyProfileOverlay_EnvPolicy verifies that a profile's env policy layers over the
+// global settings without leaking into the base config.
+func TestApplyProfileOverlay_EnvPolicy(t *testing.T) {
+	base := Config{
+		EnvWhitelist: []string{"PATH", "HOME", "AWS_*"},
+		EnvBlocklist: []string{"SSH_AUTH_SOCK"},
+	}
+	overlay := ProfileFile{
+		EnvWhitelist: []string{"PATH", "AWS_REGION"},
+		EnvBlocklist: []string{"AWS_*", "GITHUB_TOKEN"},
+		Env:          map[string]string{"CUSTOMER": "acme"},
+	}
+
+	result := ApplyProfileOverlay(base, overlay)
+
+	if !reflect.DeepEqual(result.EnvBlocklist, []string{"SSH_AUTH_SOCK", "AWS_*", "GITHUB_TOKEN"}) {
+		t.Errorf("Blocklist not layered: %v", result.EnvBlocklist)
+	}
+	if !reflect.DeepEqual(result.EnvWhitelist, base.EnvWhitelist) {
+		t.Errorf("Global whitelist should be kept, got %v", result.EnvWhitelist)
+	}
+	if !reflect.DeepEqual(result.ProfileEnvWhitelist, []string{"PATH", "AWS_REGION"}) {
+		t.Errorf("Profile whitelist not applied: %v", result.ProfileEnvWhitelist)
+	}
+	if result.Env["CUSTOMER"] != "acme" {
+		t.Errorf("Env map not applied: %v", result.Env)
+	}
+	if len(base.EnvBlocklist) != 1 {
+		t.Errorf("Base blocklist was modified: %v", base.EnvBlocklist)
+	}
+
+	// Switching to a profile without env settings must drop the previous profile's policy.
+	plain := ApplyProfileOverlay(base, ProfileFile{})
+	if plain.ProfileEnvWhitelist != nil || plain.Env != nil || len(plain.EnvBlocklist) != 1 {
+		t.Errorf("Profile env policy leaked: %+v", plain)
+	}
+}
+
+// TestApplyProfileOverlay_Timeout verifies the profile default timeout does not outlive the profile.
+func TestApplyProfileOverlay_Timeout(t *testing.T) {
+	withTimeout := ApplyProfileOverlay(Config{}, ProfileFile{Timeout: " 2m "})
+	if withTimeout.Timeout != "2m" {
+		t.Errorf("Expected profile timeout 2m, got %q", withTimeout.Timeout)
+	}
+	if plain := ApplyProfileOverlay(withTimeout, ProfileFile{}); plain.Timeout != "" {
+		t.Errorf("Profile timeout leaked: %q", plain.Timeout)
+	}
+}
+
+// TestApplyProfileOverlay_Hosts verifies host and hosts are merged and do not outlive the profile.
+func TestApplyProfileOverlay_Hosts(t *testing.T) {
+	remote := ApplyProfileOverlay(Config{}, ProfileFile{
+		Host:       "web1",
+		Hosts:      []string{" web2 ", "web1", ""},
+		SSHOptions: []string{"-p", "2222"},
+	})
+	if !reflect.DeepEqual(remote.Hosts, []string{"web1", "web2"}) {
+		t.Errorf("Unexpected hosts: %v", remote.Hosts)
+	}
+	if !reflect.DeepEqual(remote.SSHOptions, []string{"-p", "2222"}) {
+		t.Errorf("Unexpected ssh options: %v", remote.SSHOptions)
+	}
+	if local := ApplyProfileOverlay(remote, ProfileFile{}); local.Hosts != nil || local.SSHOptions != nil {
+		t.Errorf("Remote target leaked: %v %v", local.Hosts, local.SSHOptions)
+	}
+}
+
+func TestApplyProfileOverlay_Container(t *testing.T) {
+	var profile ProfileFile
+	data := `
+[container]
+name = "devbox"
+runtime = "podman"
+user = "dev"
+
+[[commands]]
+name = "Root Shell"
+command = "bash"
+container = { user = "root" }
+`
+	if err := toml.Unmarshal([]byte(data), &profile); err != nil {
+		t.Fatal(err)
+	}
+	cfg := ApplyProfileOverlay(Config{}, profile)
+	if cfg.Container == nil || cfg.Container.Name != "devbox" || cfg.Container.Runtime != "podman" {
+		t.Fatalf("Unexpected container: %+v", cfg.Container)
+	}
+	if c := cfg.Commands[0].Container; c == nil || c.User != "root" {
+		t.Errorf("Unexpected command container: %+v", c)
+	}
+	if local := ApplyProfileOverlay(cfg, ProfileFile{}); local.Container != nil {
+		t.Errorf("Container leaked: %+v", local.Container)
+	}
+}
+
 // TestLoadConfig_HandlesBrokenProfiles simulates a "Rescue Mode" scenario.
 // We create a directory with a garbage .profile.toml and ensure LoadConfig:
 // 1. Does not panic
@@ -228,3 +326,95 @@ func TestLoadConfig_HandlesBrokenConfig(t *testing.T) {
 		t.Error("Rescue config missing purge/reset command")
 	}
 }
+
+func TestBuildGrid_HidesUnmetCells(t *testing.T) {
+	cfg := Config{X: 2, Y: 1, Commands: []Command{
+		{Name: "Greyed", Col: "a", Row: 0, Unmet: "needs docker on PATH", Requires: &Requirement{Bins: []string{"docker"}}},
+		{Name: "Hidden", Col: "b", Row: 0, Unmet: "only for macos", Requires: &Requirement{OS: []string{"macos"}, Hide: true}},
+	}}
+	grid := BuildGrid(cfg)
+	if grid[0][0] != "Greyed" || grid[0][1] != "" {
+		t.Fatalf("expected unmet cells to stay unless hidden, got %v", grid)
+	}
+}
+
+func TestResolveOSVariants(t *testing.T) {
+	variants := map[string]string{
+		"linux_arch":    "sudo pacman -Syu",
+		"linux_generic": "echo generic",
+		"macos":         "brew upgrade",
+	}
+	debianOnly := map[string]string{"linux_debian": "sudo apt upgrade"}
+	tests := []struct {
+		target string
+		linux  bool
+		os     map[string]string
+		want   string
+	}{
+		{"linux_arch", true, variants, "sudo pacman -Syu"},
+		{"linux_fedora", true, variants, "echo generic"},
+		{"macos", false, variants, "brew upgrade"},
+		{"windows", false, variants, "fallback"},
+		{"linux_generic", true, debianOnly, "sudo apt upgrade"},
+		{"linux_fedora", true, debianOnly, "fallback"}, // Known distros never fall back to debian
+	}
+	for _, tt := range tests {
+		commands := []Command{{
+			Name:    "Update",
+			Command: "fallback",
+			OS:      tt.os,
+			Items:   []CommandItem{{Name: "item", OS: tt.os}},
+		}}
+		resolveOSVariants(commands, tt.target, tt.linux)
+		if commands[0].Command != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.target, tt.want, commands[0].Command)
+		}
+		if want := strings.TrimPrefix(tt.want, "fallback"); commands[0].Items[0].Command != want {
+			t.Errorf("%s: expected item command %q, got %q", tt.target, want, commands[0].Items[0].Command)
+		}
+	}
+}
+
+func TestValidatePlaceholders(t *testing.T) {
+	host := []CommandParam{{Name: "host"}}
+	tests := []struct {
+		name string
+		cmd  Command
+		want string // substring of the error; empty means valid
+	}{
+		{"bare", Command{Name: "Ping", Command: "ping -c 4 {{host}}", Params: host}, ""},
+		{"single quotes", Command{Name: "Ping", Command: "ping '{{host}}'", Params: host}, "{{host}} is inside quotes"},
+		{"double quotes", Command{Name: "Ping", Command: `echo "to {{ host }} now"`, Params: host}, "{{host}}"},
+		{"quotes closed before", Command{Name: "Ping", Command: `echo 'a' {{host}} "b"`, Params: host}, ""},
+		{"escaped quote", Command{Name: "Ping", Command: `echo \' {{host}}`, Params: host}, ""},
+		{"built-in", Command{Name: "Ls", Command: "ls \"{{cwd}}\""}, "{{cwd}}"},
+		{"unknown name", Command{Name: "Ps", Command: "docker ps --format '{{range .Mounts}}{{end}}'"}, ""},
+		{"step", Command{Name: "Deploy", Steps: []CommandStep{{Command: "ssh '{{host}}' true"}}, Params: host}, "Deploy"},
+		{"os variant", Command{Name: "Open", OS: map[string]string{"macos": "open '{{selection}}'"}}, "{{selection}}"},
+		{"item command", Command{Name: "Branch", ItemCommand: "git switch \"{{item}}\""}, "{{item}}"},
+		{"item", Command{Name: "Tools", Items: []CommandItem{{Name: "Dig", Command: "dig '{{host}}'", Params: host}}}, `"Dig"`},
+	}
+	for _, tt := range tests {
+		err := ValidatePlaceholders([]Command{tt.cmd})
+		if tt.want == "" && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: expected an error with %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestRescueConfig_PurgeCellsConfirm(t *testing.T) {
+	for _, c := range RescueConfig().Commands {
+		if !strings.HasPrefix(c.Command, "drako purge") {
+			continue
+		}
+		want := "yes"
+		if c.Command == "drako purge --config" {
+			want = "name"
+		}
+		if c.Confirm != want {
+			t.Errorf("%q: confirm = %q, want %q", c.Name, c.Confirm, want)
+		}
+	}
+}
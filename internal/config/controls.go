@@ -9,6 +9,10 @@ type InputConfig struct {
 	// Configurable single-key actions
 	Explain      string `toml:"explain"`
 	Inventory    string `toml:"inventory"`
+	Jobs         string `toml:"jobs"`
+	History      string `toml:"history"`
+	Output       string `toml:"output"`
+	DryRun       string `toml:"dry_run"`
 	PathGridMode string `toml:"path_grid_mode"`
 	Lock         string `toml:"lock"`
 	ProfilePrev  string `toml:"profile_prev"`
@@ -32,35 +32,11 @@ func WeaveConfig(templateContent, dictionaryContent []byte) ([]byte, error) {
 		// ================================================================
 		// Command Replacement
 		// ================================================================
-		// Priority Chain:
-		// 1. Specific Target (e.g. linux_arch)
-		// 2. Linux Generic (e.g. linux_generic)
-		// 3. Linux Debian (Safety net ONLY if distro is unknown/generic)
-		// 4. Error with Link
+		// Priority chain of selectVariant, then 4. Error with Link
 
-		cmd, ok := variants[targetKey]
+		cmd, ok := selectVariant(variants, targetKey, runtime.GOOS == "linux")
 		if !ok {
-			// Specific key missing. Try fallbacks.
-			if runtime.GOOS == "linux" {
-				// Try Generic first
-				if val, ok := variants["linux_generic"]; ok {
-					cmd = val
-				} else {
-					// Try Debian only if we are treating this as an unknown/generic distro
-					if targetKey == "linux_generic" {
-						if val, ok := variants["linux_debian"]; ok {
-							cmd = val
-						} else {
-							cmd = getErrorCommand(cmdName, targetKey)
-						}
-					} else {
-						// Known distro, strictly NO debian fallback
-						cmd = getErrorCommand(cmdName, targetKey)
-					}
-				}
-			} else {
-				cmd = getErrorCommand(cmdName, targetKey)
-			}
+			cmd = getErrorCommand(cmdName, targetKey)
 		}
 
 		// ================================================================
@@ -102,6 +78,52 @@ func WeaveConfig(templateContent, dictionaryContent []byte) ([]byte, error) {
 	return []byte(woven), nil
 }
 
+// selectVariant picks the command for targetKey from per-OS variants.
+// Priority Chain:
+// 1. Specific Target (e.g. linux_arch)
+// 2. Linux Generic (e.g. linux_generic)
+// 3. Linux Debian (Safety net ONLY if distro is unknown/generic)
+func selectVariant(variants map[string]string, targetKey string, linux bool) (string, bool) {
+	if cmd, ok := variants[targetKey]; ok {
+		return cmd, true
+	}
+	if !linux {
+		return "", false
+	}
+	if cmd, ok := variants["linux_generic"]; ok {
+		return cmd, true
+	}
+	// Debian only for an unknown/generic distro; known distros get NO debian fallback
+	if targetKey == "linux_generic" {
+		if cmd, ok := variants["linux_debian"]; ok {
+			return cmd, true
+		}
+	}
+	return "", false
+}
+
+// ResolveOSVariants sets the command of every cell and item with an os table to the variant for
+// this machine, chosen like WeaveConfig chooses dictionary entries. Without a matching variant the
+// command stays as configured.
+func ResolveOSVariants(commands []Command) {
+	resolveOSVariants(commands, detectRuntimeTarget(), runtime.GOOS == "linux")
+}
+
+func resolveOSVariants(commands []Command, targetKey string, linux bool) {
+	for i := range commands {
+		c := &commands[i]
+		if cmd, ok := selectVariant(c.OS, targetKey, linux); ok {
+			c.Command = cmd
+		}
+		for j := range c.Items {
+			it := &c.Items[j]
+			if cmd, ok := selectVariant(it.OS, targetKey, linux); ok {
+				it.Command = cmd
+			}
+		}
+	}
+}
+
 // ================================================================
 // Runtime Detection
 // ================================================================
@@ -114,6 +136,11 @@ var DistroKeywords = map[string][]string{
 	"linux_void":   {"void"},
 }
 
+// RuntimeTarget returns the dictionary key of this machine, e.g. "linux_debian", "macos" or "windows".
+func RuntimeTarget() string {
+	return detectRuntimeTarget()
+}
+
 func detectRuntimeTarget() string {
 	switch runtime.GOOS {
 	case "windows":
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Built-in variables, available in every command as {{name}}; core fills them in.
+const (
+	VarAssetsDir = "assets_dir" // ~/.config/drako/assets/<profile>
+	VarConfigDir = "config_dir" // ~/.config/drako
+	VarProfile   = "profile"    // name of the active profile
+	VarCwd       = "cwd"        // directory drako is in (path mode)
+	VarSelection = "selection"  // sub-directory picked in path mode, if any
+)
+
+// BuiltinVarNames lists the built-in variables.
+var BuiltinVarNames = []string{VarAssetsDir, VarConfigDir, VarProfile, VarCwd, VarSelection}
+
+// ItemPlaceholder is replaced by the item name in item_command.
+const ItemPlaceholder = "item"
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ValidatePlaceholders rejects commands that put a drako placeholder (a parameter, a built-in
+// variable or {{item}}) inside quotes. drako quotes the value itself, so the surrounding quotes
+// would end up closing it again. Placeholders that drako does not know, like the
+// {{end}} of a Go template, are left alone.
+func ValidatePlaceholders(commands []Command) error {
+	for _, c := range commands {
+		known := placeholderNames(c.Params)
+		for _, command := range commandStrings(c.Command, c.OS, c.Steps) {
+			if err := checkQuoted(c.Name, command, known); err != nil {
+				return err
+			}
+		}
+		for _, command := range []string{c.ItemsCommand, c.StatusCommand} {
+			if err := checkQuoted(c.Name, command, placeholderNames(nil)); err != nil {
+				return err
+			}
+		}
+		if err := checkQuoted(c.Name, c.ItemCommand, map[string]bool{ItemPlaceholder: true}); err != nil {
+			return err
+		}
+		for _, item := range c.Items {
+			known := placeholderNames(append(append([]CommandParam{}, c.Params...), item.Params...))
+			for _, command := range commandStrings(item.Command, item.OS, item.Steps) {
+				if err := checkQuoted(item.Name, command, known); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func checkQuoted(cell, command string, known map[string]bool) error {
+	if name := quotedPlaceholder(command, known); name != "" {
+		return fmt.Errorf("command %q: {{%s}} is inside quotes; drako quotes the value itself, so leave the quotes out", cell, name)
+	}
+	return nil
+}
+
+// placeholderNames returns the built-in variables and the given parameters.
+func placeholderNames(params []CommandParam) map[string]bool {
+	known := make(map[string]bool, len(BuiltinVarNames)+len(params))
+	for _, name := range BuiltinVarNames {
+		known[name] = true
+	}
+	for _, p := range params {
+		known[p.Name] = true
+	}
+	return known
+}
+
+// commandStrings collects a cell's command, its os variants (in a stable order) and its steps.
+func commandStrings(command string, variants map[string]string, steps []CommandStep) []string {
+	out := []string{command}
+	keys := make([]string, 0, len(variants))
+	for k := range variants {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out = append(out, variants[k])
+	}
+	for _, s := range steps {
+		out = append(out, s.Command)
+	}
+	return out
+}
+
+// quotedPlaceholder returns the name of the first known placeholder in command that sits inside
+// single or double quotes, or "". Backslash escapes count outside single quotes, as in sh.
+func quotedPlaceholder(command string, known map[string]bool) string {
+	matches := placeholderPattern.FindAllStringSubmatchIndex(command, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	var quote byte
+	pos := 0
+	for _, m := range matches {
+		for ; pos < m[0]; pos++ {
+			switch ch := command[pos]; {
+			case ch == '\\' && quote != '\'':
+				pos++
+			case quote == 0 && (ch == '\'' || ch == '"'):
+				quote = ch
+			case ch == quote:
+				quote = 0
+			}
+		}
+		if name := command[m[2]:m[3]]; quote != 0 && known[name] {
+			return name
+		}
+	}
+	return ""
+}
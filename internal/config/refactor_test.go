@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/BurntSushi/toml"
 )
 
 func TestRefactorBootstrap(t *testing.T) {
@@ -77,3 +79,25 @@ func TestRefactorBootstrap(t *testing.T) {
 	// If the weaver was broken, the TOML decode above (or in app) would likely fail or produce truncated strings.
 	// We trust that if LoadConfig succeeded with non-empty commands, we are good.
 }
+
+func TestCoreDictionary_KnownTargets(t *testing.T) {
+	data, err := bootstrapFS.ReadFile("bootstrap/core_dictionary.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dict map[string]map[string]string
+	if _, err := toml.Decode(string(data), &dict); err != nil {
+		t.Fatal(err)
+	}
+	known := map[string]bool{"macos": true, "windows": true, "linux_generic": true}
+	for key := range DistroKeywords {
+		known[key] = true
+	}
+	for name, variants := range dict {
+		for target := range variants {
+			if !known[target] {
+				t.Errorf("%s: unknown target %q", name, target)
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"embed"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 
@@ -29,38 +30,63 @@ type DracoThemeConfig struct {
 var loadedThemes map[string]DracoThemeConfig
 
 func init() {
-	userConfigDir, err := os.UserConfigDir()
+	userThemesPath, err := ThemesPath()
 	if err != nil {
 		panic(fmt.Sprintf("Failed to get user config directory: %v", err))
 	}
 
-	userThemesPath := filepath.Join(userConfigDir, "drako", "themes.toml")
-
-	var themesContent []byte
-
 	if _, err := os.Stat(userThemesPath); err == nil {
-		// User-defined themes.toml exists, load from there
-		themesContent, err = os.ReadFile(userThemesPath)
-		if err != nil {
-			panic(fmt.Sprintf("Failed to read user themes file %s: %v", userThemesPath, err))
+		// User-defined themes.toml exists, load from there. A broken file falls back to the
+		// embedded themes so drako (and drako doctor) can still start and report it.
+		themes, err := ParseThemesFile(userThemesPath)
+		if err == nil {
+			loadedThemes = themes
+			return
 		}
-	} else if os.IsNotExist(err) {
-		// User-defined themes.toml does not exist, load from embedded
-		themesContent, err = embeddedThemesFS.ReadFile("bootstrap/themes.toml")
-		if err != nil {
-			panic(fmt.Sprintf("Failed to read embedded themes file: %v", err))
-		}
-	} else {
+		log.Printf("Failed to load user themes file %s: %v (using built-in themes)", userThemesPath, err)
+	} else if !os.IsNotExist(err) {
 		// Other error checking user themes file
 		panic(fmt.Sprintf("Error checking user themes file %s: %v", userThemesPath, err))
 	}
 
-	// Decode the TOML content
+	// Load from embedded
+	themesContent, err := embeddedThemesFS.ReadFile("bootstrap/themes.toml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read embedded themes file: %v", err))
+	}
 	if _, err := toml.Decode(string(themesContent), &loadedThemes); err != nil {
 		panic(fmt.Sprintf("Failed to decode themes TOML: %v", err))
 	}
 }
 
+// ThemesPath returns the user's themes.toml, which replaces the built-in themes when present.
+func ThemesPath() (string, error) {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userConfigDir, "drako", "themes.toml"), nil
+}
+
+// ParseThemesFile reads a themes.toml file.
+func ParseThemesFile(path string) (map[string]DracoThemeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var themes map[string]DracoThemeConfig
+	if _, err := toml.Decode(string(data), &themes); err != nil {
+		return nil, err
+	}
+	return themes, nil
+}
+
+// HasTheme reports whether a theme called name is loaded.
+func HasTheme(name string) bool {
+	_, ok := loadedThemes[name]
+	return ok
+}
+
 // UIColors describes concrete UI component colors derived from a theme.
 type UIColors struct {
 	HeaderFG string
@@ -1,24 +1,101 @@
 package config
 
+// CommandParam declares a value that is prompted for before a command runs.
+// It is referenced in the command string as {{name}}.
+type CommandParam struct {
+	Name    string   `toml:"name"`
+	Type    string   `toml:"type"` // "string" (default), "choice", "path" or "number"
+	Prompt  string   `toml:"prompt"`
+	Default string   `toml:"default"`
+	Choices []string `toml:"choices"`
+}
+
+// CommandStep is one stage of a multi-step command. Steps run in order; a failing step
+// stops the rest unless it sets continue_on_error.
+type CommandStep struct {
+	Name            string `toml:"name"`
+	Command         string `toml:"command"`
+	Cwd             string `toml:"cwd"` // Resolved like the command's cwd, relative to it
+	ContinueOnError bool   `toml:"continue_on_error"`
+}
+
+// ContainerTarget runs commands inside a running container (docker/podman exec) instead of on the host.
+type ContainerTarget struct {
+	Name    string `toml:"name"`
+	Runtime string `toml:"runtime"` // "docker" or "podman"; empty picks whichever is installed, docker first
+	Shell   string `toml:"shell"`   // Shell inside the container; defaults to the profile shell
+	User    string `toml:"user"`
+	Workdir string `toml:"workdir"` // Path inside the container
+}
+
+// Requirement gates a cell or item on what the machine offers. Unmet cells are greyed out,
+// or left off the grid with hide = true.
+type Requirement struct {
+	Bins  []string `toml:"bins"`  // Executables that must be on PATH
+	OS    []string `toml:"os"`    // Runtime targets such as "macos", "windows" or "linux_arch"; "linux" matches any distro
+	Check string   `toml:"check"` // Command that must exit 0
+	Hide  bool     `toml:"hide"`
+}
+
 // CommandItem represents a single item in a command dropdown
 type CommandItem struct {
-	Name               string `toml:"name"`
-	Command            string `toml:"command"`
-	Description        string `toml:"description"`
-	AutoCloseExecution *bool  `toml:"auto_close_execution"`
-	DebugExecution     *bool  `toml:"debug_execution"`
+	Name               string            `toml:"name"`
+	Command            string            `toml:"command"`
+	OS                 map[string]string `toml:"os"` // Per-OS commands keyed like the core dictionary; the match replaces command
+	Description        string            `toml:"description"`
+	AutoCloseExecution *bool             `toml:"auto_close_execution"`
+	DebugExecution     *bool             `toml:"debug_execution"`
+	Background         *bool             `toml:"background"`
+	Cwd                string            `toml:"cwd"`       // Absolute, ~/..., @assets/... or relative to the path-mode dir
+	Env                map[string]string `toml:"env"`       // Extra variables, applied after env_file
+	EnvFile            string            `toml:"env_file"`  // dotenv file, resolved like cwd
+	Timeout            string            `toml:"timeout"`   // Go duration (e.g. "30s", "5m"); "0" disables
+	Confirm            string            `toml:"confirm"`   // "none" (default), "yes" (y/N prompt) or "name" (type the cell name)
+	Launcher           string            `toml:"launcher"`  // "inline" (default), "tmux-window", "tmux-split" or "tmux-popup"
+	Container          *ContainerTarget  `toml:"container"` // Fields set here override the profile container
+	Notify             *bool             `toml:"notify"`    // true: always notify when done, false: never; unset: notify_after decides
+	Params             []CommandParam    `toml:"params"`
+	Steps              []CommandStep     `toml:"steps"`
+	Requires           *Requirement      `toml:"requires"`
+
+	// Why Requires is not met on this machine; empty when it is (see core.ApplyRequirements).
+	Unmet string `toml:"-"`
 }
 
 // Command represents a grid command
 type Command struct {
-	Name               string        `toml:"name"`
-	Command            string        `toml:"command"`
-	Row                int           `toml:"row"`
-	Col                string        `toml:"col"`
-	Description        string        `toml:"description"`
-	AutoCloseExecution *bool         `toml:"auto_close_execution"`
-	DebugExecution     *bool         `toml:"debug_execution"`
-	Items              []CommandItem `toml:"items"`
+	Name               string            `toml:"name"`
+	Command            string            `toml:"command"`
+	OS                 map[string]string `toml:"os"` // Per-OS commands keyed like the core dictionary; the match replaces command
+	Row                int               `toml:"row"`
+	Col                string            `toml:"col"`
+	Description        string            `toml:"description"`
+	AutoCloseExecution *bool             `toml:"auto_close_execution"`
+	DebugExecution     *bool             `toml:"debug_execution"`
+	Background         *bool             `toml:"background"`
+	Cwd                string            `toml:"cwd"`       // Absolute, ~/..., @assets/... or relative to the path-mode dir
+	Env                map[string]string `toml:"env"`       // Extra variables, applied after env_file
+	EnvFile            string            `toml:"env_file"`  // dotenv file, resolved like cwd
+	Timeout            string            `toml:"timeout"`   // Go duration (e.g. "30s", "5m"); "0" disables
+	Confirm            string            `toml:"confirm"`   // "none" (default), "yes" (y/N prompt) or "name" (type the cell name)
+	Launcher           string            `toml:"launcher"`  // "inline" (default), "tmux-window", "tmux-split" or "tmux-popup"
+	Container          *ContainerTarget  `toml:"container"` // Fields set here override the profile container
+	Notify             *bool             `toml:"notify"`    // true: always notify when done, false: never; unset: notify_after decides
+	Params             []CommandParam    `toml:"params"`
+	Steps              []CommandStep     `toml:"steps"`
+	Items              []CommandItem     `toml:"items"`
+	ItemsCommand       string            `toml:"items_command"`   // Prints more items when the dropdown opens: lines, or JSON objects with name/command/description
+	ItemCommand        string            `toml:"item_command"`    // Command of a generated item; {{item}} is replaced by its name
+	ItemsTimeout       string            `toml:"items_timeout"`   // Go duration; default 10s
+	ItemsCache         string            `toml:"items_cache"`     // How long generated items are reused (Go duration); default 30s, "0" disables
+	StatusCommand      string            `toml:"status_command"`  // Probe run in the background; its exit code and first line show on the cell
+	StatusInterval     string            `toml:"status_interval"` // How often the probe runs (Go duration); default 30s
+	Requires           *Requirement      `toml:"requires"`
+
+	// Items produced by items_command at runtime; they resolve by name like Items.
+	Generated []CommandItem `toml:"-"`
+	// Why Requires is not met on this machine; empty when it is (see core.ApplyRequirements).
+	Unmet string `toml:"-"`
 }
 
 // AppSettings represents the global configuration in config.toml
@@ -31,6 +108,10 @@ type AppSettings struct {
 	EnvWhitelist       []string    `toml:"env_whitelist"`
 	EnvBlocklist       []string    `toml:"env_blocklist"`
 	Theme              string      `toml:"theme"` // Global Fallback Theme
+	GlassrootConfirm   string      `toml:"glassroot_confirm"`
+	NotifyAfter        string      `toml:"notify_after"`
+	NotifyMethod       string      `toml:"notify_method"`
+	NotifyCommand      string      `toml:"notify_command"`
 	Keys               InputConfig `toml:"keys"`
 }
 
@@ -47,8 +128,27 @@ type Config struct {
 	AutoLockEnabled    *bool       `toml:"auto_lock_enabled"`
 	EnvWhitelist       []string    `toml:"env_whitelist"`
 	EnvBlocklist       []string    `toml:"env_blocklist"`
+	GlassrootConfirm   string      `toml:"glassroot_confirm"` // Confirmation level forced on every cell in glassroot mode
+	NotifyAfter        string      `toml:"notify_after"`      // Notify when a run takes at least this long (Go duration); empty disables
+	NotifyMethod       string      `toml:"notify_method"`     // "bell" (default), "osc9", "osc777" or "command"
+	NotifyCommand      string      `toml:"notify_command"`    // Notifier for the "command" method, e.g. "notify-send"
 	Keys               InputConfig `toml:"keys"`
 	Commands           []Command   `toml:"commands"`
+
+	// Environment policy contributed by the active profile (see ApplyProfileOverlay).
+	ProfileEnvWhitelist []string          `toml:"-"`
+	Env                 map[string]string `toml:"-"`
+
+	// Default command timeout of the active profile; empty means no limit.
+	Timeout string `toml:"-"`
+	// Default launcher of the active profile; empty means inline.
+	Launcher string `toml:"-"`
+
+	// Remote targets of the active profile; commands run over SSH when set.
+	Hosts      []string `toml:"-"`
+	SSHOptions []string `toml:"-"`
+	// Container the active profile's commands run in, if any.
+	Container *ContainerTarget `toml:"-"`
 }
 
 // ProfileFile represents the content of a profile file (e.g. core.profile.toml)
@@ -60,6 +160,23 @@ type ProfileFile struct {
 	Shell     *string   `toml:"shell"`
 	Assets    *[]string `toml:"assets"`
 	Commands  []Command `toml:"commands"`
+
+	// Environment policy, layered over the global settings
+	EnvWhitelist []string          `toml:"env_whitelist"`
+	EnvBlocklist []string          `toml:"env_blocklist"`
+	Env          map[string]string `toml:"env"`
+
+	// Defaults for commands that do not set their own
+	Timeout  string `toml:"timeout"`
+	Launcher string `toml:"launcher"`
+
+	// Remote execution: commands run on host (or one of hosts, picked on run) over SSH
+	Host       string   `toml:"host"`
+	Hosts      []string `toml:"hosts"`
+	SSHOptions []string `toml:"ssh_options"` // Extra ssh arguments, e.g. ["-p", "2222"]
+
+	// Container execution: commands run via docker/podman exec
+	Container *ContainerTarget `toml:"container"`
 }
 
 // ProfileInfo holds metadata and content of a profile
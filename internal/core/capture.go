@@ -0,0 +1,121 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// maxCaptures caps how many debug capture files are kept; older ones are pruned.
+const maxCaptures = 50
+
+var captureNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// capturesDir returns the directory holding the output captured by debug runs.
+func capturesDir() (string, error) {
+	cfgDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfgDir, "captures"), nil
+}
+
+// newCapture creates the capture file for a debug run of name and writes its header.
+func newCapture(name string, argv []string) (*os.File, error) {
+	dir, err := capturesDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	pruneCaptures(dir, maxCaptures-1)
+
+	now := time.Now()
+	slug := strings.Trim(captureNameUnsafe.ReplaceAllString(name, "-"), "-")
+	if slug == "" {
+		slug = "command"
+	}
+	f, err := os.CreateTemp(dir, fmt.Sprintf("%s-%s-*.log", now.Format("20060102-150405"), slug))
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(f, "# drako capture: %s\n# exec: %s\n# started: %s\n\n", name, strings.Join(argv, " "), now.Format("2006-01-02 15:04:05"))
+	return f, nil
+}
+
+// pruneCaptures removes the oldest capture files so that at most keep remain.
+func pruneCaptures(dir string, keep int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var logs []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") {
+			logs = append(logs, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(logs) <= keep {
+		return
+	}
+	// Names start with a sortable timestamp, so lexical order is chronological.
+	sort.Strings(logs)
+	for _, path := range logs[:len(logs)-keep] {
+		if err := os.Remove(path); err != nil {
+			log.Printf("Failed to prune capture %s: %v", path, err)
+		}
+	}
+}
+
+// runDebug runs cmd with its combined output streamed to the terminal as it arrives and
+// written to a capture file, whose path is returned. A failure is noted at the end of the
+// capture. If no capture file can be created the output is only streamed.
+func runDebug(cmd *exec.Cmd, name string, timeout time.Duration) (string, error) {
+	f, err := newCapture(name, cmd.Args)
+	if err != nil {
+		log.Printf("could not create capture for %s: %v", name, err)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stdout
+		return "", runWithTimeout(cmd, timeout, false)
+	}
+	defer f.Close()
+
+	out := io.MultiWriter(os.Stdout, f)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err = runWithTimeout(cmd, timeout, false)
+	if err != nil {
+		fmt.Fprintf(f, "\n%s\nError: %v\n", failureBanner(err), err)
+	}
+	return f.Name(), err
+}
+
+// ReadCapture returns the last n lines of a capture file (all lines if n <= 0).
+func ReadCapture(path string, n int) ([]string, error) {
+	return tailFile(path, n)
+}
+
+// LastCapture returns the most recent history entry of cell that has captured output.
+// Empty profile and cell match any.
+func LastCapture(profile, cell string) (HistoryEntry, bool) {
+	entries, err := ReadHistory(HistoryFilter{Profile: profile, Cell: cell})
+	if err != nil {
+		return HistoryEntry{}, false
+	}
+	for _, e := range entries {
+		if (cell == "" || e.Cell == cell) && e.Output != "" {
+			return e, true
+		}
+	}
+	return HistoryEntry{}, false
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestRunCommand_DebugCapturesOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) {}
+
+	debug := true
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{{
+			Name:           "Scan",
+			Command:        "echo found; echo oops >&2; exit 3",
+			DebugExecution: &debug,
+		}},
+	}
+	RunCommandWith(cfg, "Scan", RunOptions{Profile: "net"})
+
+	e, ok := LastCapture("net", "Scan")
+	if !ok {
+		t.Fatal("expected the debug run to be recorded with a capture")
+	}
+	if e.Mode != ModeDebug || e.ExitCode != 3 {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	lines, err := ReadCapture(e.Output, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(lines, "\n")
+	if !strings.HasPrefix(got, "# drako capture: Scan") || !strings.Contains(got, "found") || !strings.Contains(got, "oops") {
+		t.Fatalf("unexpected capture:\n%s", got)
+	}
+	if tail, _ := ReadCapture(e.Output, 1); len(tail) != 1 || tail[0] != "Error: exit status 3" {
+		t.Fatalf("expected the failure noted on the last line, got %q", tail)
+	}
+}
+
+func TestPruneCaptures(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("20240101-00000%d-cell.log", i))
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pruneCaptures(dir, 2)
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 || entries[0].Name() != "20240101-000003-cell.log" {
+		t.Fatalf("expected the two newest captures, got %v", entries)
+	}
+}
@@ -1,11 +1,11 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,9 +15,10 @@ import (
 )
 
 var (
-	pauseFn    = pause
-	lookPathFn = exec.LookPath
-	commandFn  = exec.Command
+	pauseFn         = pause
+	lookPathFn      = exec.LookPath
+	commandFn       = exec.Command
+	stdinIsTerminal = func() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
 	//setenvFn   = os.Setenv
 	//unsetenvFn = os.Unsetenv
 )
@@ -57,7 +58,8 @@ func pause(msg string) {
 }
 
 // FindCommandByName returns a pointer to the matching top-level command or a nested item.
-// If an item is returned, the parent command is also returned.
+// If an item is returned, the parent command is also returned. Items generated by items_command
+// are not matched here; they are only found through their parent (see FindCommandIn).
 func FindCommandByName(cfg config.Config, name string) (parent *config.Command, item *config.CommandItem, ok bool) {
 	for i := range cfg.Commands {
 		c := &cfg.Commands[i]
@@ -73,6 +75,29 @@ func FindCommandByName(cfg config.Config, name string) (parent *config.Command,
 	return nil, nil, false
 }
 
+// FindCommandIn is FindCommandByName limited to the items, static or generated, of the cell
+// called parent. An empty parent falls back to FindCommandByName.
+func FindCommandIn(cfg config.Config, parent, name string) (*config.Command, *config.CommandItem, bool) {
+	if parent == "" {
+		return FindCommandByName(cfg, name)
+	}
+	for i := range cfg.Commands {
+		c := &cfg.Commands[i]
+		if c.Name != parent {
+			continue
+		}
+		for _, items := range [][]config.CommandItem{c.Items, c.Generated} {
+			for j := range items {
+				if items[j].Name == name {
+					return c, &items[j], true
+				}
+			}
+		}
+		break
+	}
+	return nil, nil, false
+}
+
 // - exec.Cmd is like subprocess, constructed with argv (no implicit shell).
 // - We explicitly ask for a shell with buildShellCmd.
 
@@ -98,129 +123,349 @@ func buildShellCmd(shell_config, commandStr string) *exec.Cmd {
 	}
 }
 
-// RunCommand finds the selected command from the loaded config and executes it.
-func RunCommand(cfg config.Config, selected string) {
-	// Handle special internal commands first
-	if strings.HasPrefix(selected, "drako purge") {
-		handleInternalPurge(selected)
-		// Since purge often resets state or exits, we might want to just return here
-		// But standard purge flow ends with Exit(0) usually.
-		// If it returns, we might want to pause.
-		return
-	}
+// RunOptions carries the runtime context of an execution request that is not part of the config.
+type RunOptions struct {
+	// Params holds the values collected for the command's declared parameters, keyed by name.
+	Params map[string]string
+	// Profile is the name of the active profile, recorded in history.
+	Profile string
+	// Replay, when set, re-executes a recorded history entry instead of resolving the cell from the config.
+	Replay *HistoryEntry
+	// Selection is the sub-directory picked in path mode, exposed as {{selection}}.
+	Selection string
+	// Host picks the target when the profile declares several hosts.
+	Host string
+	// Parent names the dropdown cell of the selected item, so that an item of another cell
+	// (or a generated one) with the same name is never picked instead.
+	Parent string
+	// DryRun prints what would be executed instead of running it.
+	DryRun bool
+
+	// detached marks a run without stdin (background jobs), so ssh and container exec
+	// neither attach stdin nor ask for a terminal.
+	detached bool
+}
 
-	if strings.HasPrefix(selected, "drako open") {
-		cli.HandleOpenCommand(selected)
-		return
+// CommandSpec is the effective definition of a selected cell, whether it is a
+// top-level command or an item of a dropdown.
+type CommandSpec struct {
+	Name               string
+	Command            string
+	AutoCloseExecution *bool
+	DebugExecution     *bool
+	Background         *bool
+	Cwd                string
+	Env                map[string]string
+	EnvFile            string
+	Timeout            string
+	Confirm            string
+	Launcher           string
+	Container          *config.ContainerTarget
+	Notify             *bool
+	Params             []config.CommandParam
+	Steps              []config.CommandStep
+}
+
+// ResolveCommandSpec flattens the result of FindCommandByName into a CommandSpec.
+// Items carry their own execution settings; only the working directory, environment,
+// timeout, confirmation, launcher, container and notification are inherited from the
+// parent, so a dropdown can share them across its items.
+func ResolveCommandSpec(parent *config.Command, item *config.CommandItem) CommandSpec {
+	if item != nil {
+		spec := CommandSpec{
+			Name:               item.Name,
+			Command:            item.Command,
+			AutoCloseExecution: item.AutoCloseExecution,
+			DebugExecution:     item.DebugExecution,
+			Background:         item.Background,
+			Cwd:                item.Cwd,
+			Env:                item.Env,
+			EnvFile:            item.EnvFile,
+			Timeout:            item.Timeout,
+			Confirm:            item.Confirm,
+			Launcher:           item.Launcher,
+			Container:          item.Container,
+			Notify:             item.Notify,
+			Params:             item.Params,
+			Steps:              item.Steps,
+		}
+		if parent != nil {
+			if spec.Cwd == "" {
+				spec.Cwd = parent.Cwd
+			}
+			if spec.EnvFile == "" {
+				spec.EnvFile = parent.EnvFile
+			}
+			if spec.Timeout == "" {
+				spec.Timeout = parent.Timeout
+			}
+			if spec.Confirm == "" {
+				spec.Confirm = parent.Confirm
+			}
+			if spec.Launcher == "" {
+				spec.Launcher = parent.Launcher
+			}
+			if spec.Container == nil {
+				spec.Container = parent.Container
+			}
+			if spec.Notify == nil {
+				spec.Notify = parent.Notify
+			}
+			if len(parent.Env) > 0 {
+				env := make(map[string]string, len(parent.Env)+len(item.Env))
+				for k, v := range parent.Env {
+					env[k] = v
+				}
+				for k, v := range item.Env {
+					env[k] = v
+				}
+				spec.Env = env
+			}
+		}
+		return spec
 	}
+	if parent == nil {
+		return CommandSpec{}
+	}
+	return CommandSpec{
+		Name:               parent.Name,
+		Command:            parent.Command,
+		AutoCloseExecution: parent.AutoCloseExecution,
+		DebugExecution:     parent.DebugExecution,
+		Background:         parent.Background,
+		Cwd:                parent.Cwd,
+		Env:                parent.Env,
+		EnvFile:            parent.EnvFile,
+		Timeout:            parent.Timeout,
+		Confirm:            parent.Confirm,
+		Launcher:           parent.Launcher,
+		Container:          parent.Container,
+		Notify:             parent.Notify,
+		Params:             parent.Params,
+		Steps:              parent.Steps,
+	}
+}
 
-	// cmd will hold the prepared command to run. It's a pointer type; zero value is nil.
-	var cmd *exec.Cmd
-	// Pointers to per-command overrides; nil means "use default".
-	var autoClosePtr *bool
-	var debugPtr *bool
+var (
+	errNoCommandConfigured = errors.New("no command configured")
+	errExecutableNotFound  = errors.New("executable not found in PATH")
+	errStepsNeedForeground = errors.New("multi-step commands can only run in the foreground")
+)
+
+// prepareCommand resolves the selected cell into an *exec.Cmd without running it.
+// Configured commands go through the shell with their parameters expanded; anything else
+// is looked up in PATH and executed directly (no shell).
+func prepareCommand(cfg config.Config, selected string, opts RunOptions) (*exec.Cmd, CommandSpec, error) {
+	if opts.Replay != nil {
+		return prepareReplay(cfg, *opts.Replay)
+	}
 
 	// Default shell to use for string commands (honors config/profile).
 	shell_config := cfg.DefaultShell
 
 	// Resolve a top-level command or nested item by name.
-	parentCmd, itemCfg, found := FindCommandByName(cfg, selected)
+	parentCmd, itemCfg, found := FindCommandIn(cfg, opts.Parent, selected)
 	if found {
-		if itemCfg == nil {
-			// top-level command
-			commandStr := parentCmd.Command
-			if commandStr != "" {
-				cmd = buildShellCmd(shell_config, commandStr)
-				autoClosePtr = parentCmd.AutoCloseExecution
-				debugPtr = parentCmd.DebugExecution
-			}
-		} else {
-			// dropdown item
-			commandStr := itemCfg.Command
-			if commandStr != "" {
-				cmd = buildShellCmd(shell_config, commandStr)
-				autoClosePtr = itemCfg.AutoCloseExecution
-				debugPtr = itemCfg.DebugExecution
-			}
+		spec := ResolveCommandSpec(parentCmd, itemCfg)
+		// Steps are run one by one by runPipeline and have no single *exec.Cmd.
+		if len(spec.Steps) > 0 {
+			return nil, spec, errStepsNeedForeground
+		}
+		// A config match without a command string is not retried via PATH.
+		if spec.Command == "" {
+			return nil, spec, errNoCommandConfigured
 		}
-	}
-
-	// If we didn't find a prepared command to run via a shell:
-	// - If a config match existed but had no command string, don't try PATH; just inform and return.
-	// - Otherwise, try to execute the "selected" token directly as a binary in PATH (no shell).
-	if cmd == nil {
-		if found {
-			log.Printf("No command configured for: %s", selected)
-			fmt.Printf("\n--- No Command Configured ---\n")
-			fmt.Printf("Command: '%s'\n", selected)
-			pauseFn("\nPress any key to return to the application.")
 
-			return
+		values, err := commandValues(spec, opts)
+		if err != nil {
+			return nil, spec, err
 		}
-		if path, err := lookPathFn(selected); err == nil {
-			// This is like subprocess.run([path]) in Python; argv is literal (no shell).
-			cmd = commandFn(path)
-		} else {
-			log.Printf("Executable not found in PATH: %s", selected)
-			return
+		commandStr, err := ExpandParams(spec.Command, shell_config, values)
+		if err != nil {
+			return nil, spec, err
+		}
+		cmd, err := shellCommand(cfg, spec, opts, commandStr, "")
+		if err != nil {
+			return nil, spec, err
 		}
+		return cmd, spec, nil
+	}
+
+	path, err := lookPathFn(selected)
+	if err != nil {
+		return nil, CommandSpec{Name: selected}, errExecutableNotFound
 	}
+	// This is like subprocess.run([path]) in Python; argv is literal (no shell).
+	spec := CommandSpec{Name: selected}
+	cmd := commandFn(path)
+	if err := applyCommandContext(cmd, cfg, spec, opts); err != nil {
+		return nil, spec, err
+	}
+	return cmd, spec, nil
+}
+
+// commandValues returns the values for a command's {{placeholders}}: its parameters and the built-ins.
+// They are quoted for the target shell by ExpandParams before they reach buildShellCmd.
+func commandValues(spec CommandSpec, opts RunOptions) (map[string]string, error) {
+	values, err := ResolveParamValues(spec.Params, opts.Params)
+	if err != nil {
+		return nil, err
+	}
+	for name, v := range BuiltinVars(opts) {
+		values[name] = v
+	}
+	return values, nil
+}
 
-	// --- LOGGING START ---
-	// Log the command execution to history.log in the config directory
-	// We do this best-effort; failures to log should not stop execution.
-	func() {
-		cfgDir, err := config.GetConfigDir()
+// applyCommandContext sets the working directory and environment of cmd from the spec.
+// The environment is sanitized by the env policy, then the DRAKO_* built-ins and finally
+// the command's own env_file/env values are applied. A cmd.Dir that is already set
+// (e.g. by a replay) is kept; relative paths resolve against the current directory,
+// which path mode keeps in sync with the browser.
+func applyCommandContext(cmd *exec.Cmd, cfg config.Config, spec CommandSpec, opts RunOptions) error {
+	base, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if cmd.Dir == "" && strings.TrimSpace(spec.Cwd) != "" {
+		dir, err := ResolveWorkDir(spec.Cwd, base, opts.Profile)
 		if err != nil {
-			log.Printf("logging error: could not get config dir: %v", err)
-			return
+			return err
 		}
+		cmd.Dir = dir
+	}
+	// A relative env_file sits next to the command, so it resolves against the final cwd.
+	if cmd.Dir != "" {
+		base = cmd.Dir
+	}
+	overrides, err := CommandEnvOverrides(spec, base, opts.Profile)
+	if err != nil {
+		return err
+	}
+	cmd.Env = MergeEnv(MergeEnv(CommandEnv(cfg), BuiltinEnv(BuiltinVars(opts))), overrides)
+	return nil
+}
 
-		// Ensure the directory exists (it should, but safety first)
-		if err := os.MkdirAll(cfgDir, 0o755); err != nil {
-			log.Printf("logging error: could not create config dir: %v", err)
-			return
+// prepareReplay rebuilds a recorded execution from its argv, in the directory it originally ran in.
+// Settings such as auto-close still come from the cell if it exists in the current config.
+func prepareReplay(cfg config.Config, e HistoryEntry) (*exec.Cmd, CommandSpec, error) {
+	spec := CommandSpec{Name: e.Cell}
+	if parent, item, found := FindCommandByName(cfg, e.Cell); found {
+		spec = ResolveCommandSpec(parent, item)
+	}
+	if len(e.Argv) == 0 {
+		return nil, spec, errNoCommandConfigured
+	}
+	if e.Cwd != "" {
+		if info, err := os.Stat(e.Cwd); err != nil || !info.IsDir() {
+			return nil, spec, fmt.Errorf("original directory %s is no longer available", e.Cwd)
 		}
+	}
+
+	cmd := commandFn(e.Argv[0], e.Argv[1:]...)
+	cmd.Dir = e.Cwd
+	if err := applyCommandContext(cmd, cfg, spec, RunOptions{Profile: e.Profile}); err != nil {
+		return nil, spec, err
+	}
+	return cmd, spec, nil
+}
 
-		logPath := filepath.Join(cfgDir, "history.log")
-		// Rotate if > 1MB
-		RotateLogIfNeeded(logPath, 1024*1024)
+// RunCommand finds the selected command from the loaded config and executes it.
+func RunCommand(cfg config.Config, selected string) {
+	RunCommandWith(cfg, selected, RunOptions{})
+}
 
-		f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+// RunCommandWith is RunCommand with the runtime context collected by the caller (e.g. parameter values).
+func RunCommandWith(cfg config.Config, selected string, opts RunOptions) {
+	if opts.DryRun {
+		report, err := DryRun(cfg, selected, opts)
 		if err != nil {
-			log.Printf("logging error: could not open history.log: %v", err)
-			return
+			fmt.Printf("\n--- Dry Run: %s ---\n", selected)
+			fmt.Printf("Error: %v\n", err)
+		} else {
+			fmt.Printf("\n%s", FormatDryRun(report, true))
 		}
-		defer f.Close()
+		pauseFn("\nNothing was executed. Press any key to return to the application.")
+		return
+	}
 
-		timestamp := time.Now().Format("2006-01-02 15:04:05")
+	// Handle special internal commands first; an item of a dropdown is never one of them.
+	if opts.Parent == "" && strings.HasPrefix(selected, "drako purge") {
+		handleInternalPurge(selected)
+		// Since purge often resets state or exits, we might want to just return here
+		// But standard purge flow ends with Exit(0) usually.
+		// If it returns, we might want to pause.
+		return
+	}
 
-		var executedStr string
-		if cmd.Args != nil {
-			executedStr = strings.Join(cmd.Args, " ")
-		}
+	if opts.Parent == "" && strings.HasPrefix(selected, "drako open") {
+		cli.HandleOpenCommand(selected)
+		return
+	}
 
-		entry := fmt.Sprintf("[%s] %s (exec: %s)\n", timestamp, selected, executedStr)
-		if _, err := f.WriteString(entry); err != nil {
-			log.Printf("logging error: count not write to history.log: %v", err)
+	if opts.Replay == nil {
+		if parent, item, found := FindCommandIn(cfg, opts.Parent, selected); found {
+			if spec := ResolveCommandSpec(parent, item); len(spec.Steps) > 0 {
+				runPipeline(cfg, spec, opts)
+				return
+			}
 		}
-	}()
-	// --- LOGGING END ---
+	}
+
+	cmd, spec, err := prepareCommand(cfg, selected, opts)
+	var timeout time.Duration
+	if err == nil {
+		timeout, err = CommandTimeout(cfg, spec)
+	}
+	switch {
+	case errors.Is(err, errNoCommandConfigured):
+		log.Printf("No command configured for: %s", selected)
+		fmt.Printf("\n--- No Command Configured ---\n")
+		fmt.Printf("Command: '%s'\n", selected)
+		pauseFn("\nPress any key to return to the application.")
+		return
+	case errors.Is(err, errExecutableNotFound):
+		log.Printf("Executable not found in PATH: %s", selected)
+		return
+	case err != nil:
+		log.Printf("Could not prepare %s: %v", selected, err)
+		fmt.Printf("\n--- Command Not Started ---\n")
+		fmt.Printf("Command: '%s'\n", selected)
+		fmt.Printf("Error: %v\n", err)
+		pauseFn("\nPress any key to return to the application.")
+		return
+	}
+
+	// Pointers to per-command overrides; nil means "use default".
+	autoClosePtr := spec.AutoCloseExecution
+	debugPtr := spec.DebugExecution
 
 	// Resolve flags after overrides may have been set.
 	autoClose := boolOrDefault(autoClosePtr, true)
 	debug := boolOrDefault(debugPtr, false)
 
+	// The history entry is written once the command has finished, so it can carry the outcome.
+	entry := newHistoryEntry(cfg, opts, spec, cmd, ModeLive)
+
 	if debug {
-		// Debug: capture combined output and pause.
-		output, err := cmd.CombinedOutput()
+		// Debug: stream combined output while capturing it, then pause.
+		entry.Mode = ModeDebug
 		fmt.Printf("\n--- Command Output ---\n")
 		fmt.Printf("Command: '%s'\n\n", selected)
-		fmt.Print(string(output))
+		capture, err := runDebug(cmd, spec.Name, timeout)
+		entry.Output = capture
+		done := finishHistoryEntry(entry, cmd, err)
+		recordHistory(done)
+		notifyDone(cfg, spec, done.ExitCode, err, done.Duration())
 		if err != nil {
-			fmt.Printf("\n--- Command Failed ---\n")
+			fmt.Printf("\n%s\n", failureBanner(err))
 			fmt.Printf("Error: %v\n", err)
 		}
+		if capture != "" {
+			// The TUI opens the capture in its output viewer, so there is no need to pause.
+			fmt.Printf("\nOutput saved to %s\n", capture)
+			return
+		}
 		pauseFn("\nPress any key to return to the application.")
 		return
 	}
@@ -230,13 +475,12 @@ func RunCommand(cfg config.Config, selected string) {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	// Sanitize environment variables
-	// If EnvWhitelist is configured, we restrict the environment.
-	// Otherwise, we inherit the full parent environment (pass-through).
-	cmd.Env = PrepareEnv(os.Environ(), cfg.EnvWhitelist)
-
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("\n--- Command Failed ---\n")
+	err = runWithTimeout(cmd, timeout, true)
+	done := finishHistoryEntry(entry, cmd, err)
+	recordHistory(done)
+	notifyDone(cfg, spec, done.ExitCode, err, done.Duration())
+	if err != nil {
+		fmt.Printf("\n%s\n", failureBanner(err))
 		fmt.Printf("Command: '%s'\n", selected)
 		fmt.Printf("Error: %v\n", err)
 		pauseFn("\nPress any key to return to the application.")
@@ -254,6 +498,54 @@ func RunCommand(cfg config.Config, selected string) {
 	}
 }
 
+// failureBanner is the headline printed when a command does not succeed.
+func failureBanner(err error) string {
+	if errors.Is(err, errTimedOut) {
+		return "--- Command Timed Out ---"
+	}
+	return "--- Command Failed ---"
+}
+
+// newHistoryEntry captures the context of an execution that is about to start.
+func newHistoryEntry(cfg config.Config, opts RunOptions, spec CommandSpec, cmd *exec.Cmd, mode string) HistoryEntry {
+	cwd := cmd.Dir
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	profile, shell := opts.Profile, ""
+	if opts.Replay != nil {
+		// A re-run belongs to the profile that produced the original entry.
+		if opts.Replay.Profile != "" {
+			profile = opts.Replay.Profile
+		}
+		shell = opts.Replay.Shell
+	} else if spec.Command != "" {
+		shell = cfg.DefaultShell
+	}
+	return HistoryEntry{
+		Profile:  profile,
+		Cell:     spec.Name,
+		Argv:     cmd.Args,
+		Cwd:      cwd,
+		Shell:    shell,
+		Mode:     mode,
+		Start:    time.Now(),
+		ExitCode: -1,
+	}
+}
+
+// finishHistoryEntry fills in the outcome of a finished execution.
+func finishHistoryEntry(e HistoryEntry, cmd *exec.Cmd, runErr error) HistoryEntry {
+	e.End = time.Now()
+	if cmd.ProcessState != nil {
+		e.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if runErr != nil {
+		e.Error = runErr.Error()
+	}
+	return e
+}
+
 func handleInternalPurge(command string) {
 	// Parse the command string
 	// Expected format: "drako purge --target core" or "drako purge --interactive"
@@ -72,6 +72,8 @@ func TestRunCommand_ConfigMatchButEmptyCommand(t *testing.T) {
 }
 
 func TestRunCommand_PathFallback(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
 	// Save original seams
 	oldPause, oldLook, oldCmd := pauseFn, lookPathFn, commandFn
 	defer func() { pauseFn, lookPathFn, commandFn = oldPause, oldLook, oldCmd }()
@@ -0,0 +1,34 @@
+package core
+
+import "strings"
+
+// Confirmation levels a cell can require before it runs.
+const (
+	ConfirmNone = "none"
+	ConfirmYes  = "yes"  // y/N prompt
+	ConfirmName = "name" // type the cell name back
+)
+
+// ConfirmLevel normalizes a confirm setting. Anything unrecognized is treated as "yes",
+// so a typo in the profile never silently drops a guard.
+func ConfirmLevel(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", ConfirmNone, "no", "false":
+		return ConfirmNone
+	case ConfirmName:
+		return ConfirmName
+	default:
+		return ConfirmYes
+	}
+}
+
+// EffectiveConfirm returns the stricter of the cell's own level and minimum
+// (e.g. the level glassroot mode forces on every cell).
+func EffectiveConfirm(spec CommandSpec, minimum string) string {
+	rank := map[string]int{ConfirmNone: 0, ConfirmYes: 1, ConfirmName: 2}
+	own, forced := ConfirmLevel(spec.Confirm), ConfirmLevel(minimum)
+	if rank[forced] > rank[own] {
+		return forced
+	}
+	return own
+}
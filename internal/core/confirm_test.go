@@ -0,0 +1,23 @@
+package core
+
+import "testing"
+
+func TestEffectiveConfirm(t *testing.T) {
+	tests := []struct {
+		own, minimum, want string
+	}{
+		{"", "", ConfirmNone},
+		{"none", "", ConfirmNone},
+		{"yes", "", ConfirmYes},
+		{"NAME", "", ConfirmName},
+		{"typo", "", ConfirmYes},
+		{"", "yes", ConfirmYes},
+		{"name", "yes", ConfirmName},
+		{"yes", "name", ConfirmName},
+	}
+	for _, tc := range tests {
+		if got := EffectiveConfirm(CommandSpec{Confirm: tc.own}, tc.minimum); got != tc.want {
+			t.Errorf("EffectiveConfirm(%q, %q) = %q, want %q", tc.own, tc.minimum, got, tc.want)
+		}
+	}
+}
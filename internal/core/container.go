@@ -0,0 +1,168 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// containerRuntimes are the supported runtimes, in the order they are tried when a
+// container does not name one.
+var containerRuntimes = []string{"docker", "podman"}
+
+var errContainerNotRunning = errors.New("is not running")
+
+// containerCheckTimeout bounds the `inspect` call of CheckContainer, so a hung daemon is reported
+// rather than waited on.
+var containerCheckTimeout = 5 * time.Second
+
+// EffectiveContainer merges a command's container settings over the profile's.
+// It returns nil when the command does not run in a container.
+func EffectiveContainer(cfg config.Config, spec CommandSpec) *config.ContainerTarget {
+	var target config.ContainerTarget
+	for _, c := range []*config.ContainerTarget{cfg.Container, spec.Container} {
+		if c == nil {
+			continue
+		}
+		if v := strings.TrimSpace(c.Name); v != "" {
+			target.Name = v
+		}
+		if v := strings.TrimSpace(c.Runtime); v != "" {
+			target.Runtime = v
+		}
+		if v := strings.TrimSpace(c.Shell); v != "" {
+			target.Shell = v
+		}
+		if v := strings.TrimSpace(c.User); v != "" {
+			target.User = v
+		}
+		if v := strings.TrimSpace(c.Workdir); v != "" {
+			target.Workdir = v
+		}
+	}
+	if target.Name == "" {
+		return nil
+	}
+	return &target
+}
+
+// ContainerRuntime resolves the runtime binary for target through PATH.
+func ContainerRuntime(target config.ContainerTarget) (string, error) {
+	if target.Runtime != "" {
+		known := false
+		for _, r := range containerRuntimes {
+			known = known || r == target.Runtime
+		}
+		if !known {
+			return "", fmt.Errorf("unknown container runtime %q (use %s)", target.Runtime, strings.Join(containerRuntimes, " or "))
+		}
+		path, err := lookPathFn(target.Runtime)
+		if err != nil {
+			return "", fmt.Errorf("container runtime %s: %w", target.Runtime, errExecutableNotFound)
+		}
+		return path, nil
+	}
+	for _, r := range containerRuntimes {
+		if path, err := lookPathFn(r); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no container runtime found (install %s)", strings.Join(containerRuntimes, " or "))
+}
+
+// CheckContainer reports whether target's container exists and is running, so a cell
+// can say so up front instead of failing inside the shell.
+func CheckContainer(target config.ContainerTarget) error {
+	runtime, err := ContainerRuntime(target)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	cmd := commandFn(runtime, "inspect", "--format", "{{.State.Running}}", target.Name)
+	cmd.Stdout = &out
+	if err := runWithTimeout(cmd, containerCheckTimeout, false); errors.Is(err, errTimedOut) {
+		return fmt.Errorf("%s did not answer about container %s", runtime, target.Name)
+	} else if err != nil {
+		return fmt.Errorf("container %s not found", target.Name)
+	}
+	if strings.TrimSpace(out.String()) != "true" {
+		return fmt.Errorf("container %s %w", target.Name, errContainerNotRunning)
+	}
+	return nil
+}
+
+// containerCommand wraps a command string in `<runtime> exec` for target, with -i when stdin is
+// attached and -t when it is a terminal. The command's cwd is a path inside the container
+// (defaulting to the container's workdir) and its env/env_file values are passed by name with
+// --env, the values going through the runtime's environment. The env policy applies to the
+// local runtime process.
+func containerCommand(cfg config.Config, spec CommandSpec, opts RunOptions, target config.ContainerTarget, commandStr, stepCwd string) (*exec.Cmd, error) {
+	if err := CheckContainer(target); err != nil {
+		return nil, err
+	}
+	runtime, err := ContainerRuntime(target)
+	if err != nil {
+		return nil, err
+	}
+
+	cwd := strings.TrimSpace(spec.Cwd)
+	if cwd == "" {
+		cwd = target.Workdir
+	}
+	dir := remoteDir(cwd, strings.TrimSpace(stepCwd))
+	if dir == assetsPrefix || strings.HasPrefix(dir, assetsPrefix+"/") {
+		return nil, fmt.Errorf("cwd %q: %s is not available inside containers", dir, assetsPrefix)
+	}
+	base, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := CommandEnvOverrides(spec, base, opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"exec"}
+	if !opts.detached {
+		args = append(args, "-i")
+	}
+	if attachTerminal(opts) {
+		args = append(args, "-t")
+	}
+	if target.User != "" {
+		args = append(args, "--user", target.User)
+	}
+	if dir != "" {
+		args = append(args, "--workdir", dir)
+	}
+	// Only names go on the command line; the runtime takes the values from its own environment,
+	// so they do not show up in ps, job logs or history.
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--env", k)
+	}
+	shell := target.Shell
+	if shell == "" {
+		shell = cfg.DefaultShell
+	}
+	args = append(args, target.Name)
+	args = append(args, buildShellCmd(shell, commandStr).Args...)
+
+	cmd := commandFn(runtime, args...)
+	cmd.Env = CommandEnv(cfg)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+overrides[k])
+	}
+	return cmd, nil
+}
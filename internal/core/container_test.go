@@ -0,0 +1,171 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// stubContainerRuntime makes podman the only installed runtime, answers `podman inspect` with
+// running and lets `podman exec` succeed without doing anything. stdin counts as a terminal.
+func stubContainerRuntime(t *testing.T, running string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	oldLook, oldCmd, oldTerminal := lookPathFn, commandFn, stdinIsTerminal
+	t.Cleanup(func() { lookPathFn, commandFn, stdinIsTerminal = oldLook, oldCmd, oldTerminal })
+	stdinIsTerminal = func() bool { return true }
+
+	lookPathFn = func(name string) (string, error) {
+		if name == "podman" {
+			return "/usr/bin/podman", nil
+		}
+		return "", fmt.Errorf("%s not found", name)
+	}
+	commandFn = func(name string, args ...string) *exec.Cmd {
+		if len(args) > 0 && args[0] == "inspect" {
+			switch running {
+			case "":
+				return exec.Command("sh", "-c", "exit 1")
+			case "hang":
+				return exec.Command("sh", "-c", "sleep 5")
+			}
+			return exec.Command("sh", "-c", "echo "+running)
+		}
+		cmd := exec.Command(name, args...)
+		if truePath, err := exec.LookPath("true"); err == nil {
+			cmd.Path, cmd.Err = truePath, nil
+		}
+		return cmd
+	}
+}
+
+func TestEffectiveContainer(t *testing.T) {
+	cfg := config.Config{Container: &config.ContainerTarget{Name: "devbox", User: "dev", Workdir: "/work"}}
+
+	if got := EffectiveContainer(config.Config{}, CommandSpec{}); got != nil {
+		t.Fatalf("expected no container, got %+v", got)
+	}
+	got := EffectiveContainer(cfg, CommandSpec{Container: &config.ContainerTarget{User: "root"}})
+	if got == nil || got.Name != "devbox" || got.User != "root" || got.Workdir != "/work" {
+		t.Fatalf("expected command fields over profile fields, got %+v", got)
+	}
+	got = EffectiveContainer(config.Config{}, CommandSpec{Container: &config.ContainerTarget{Name: "db"}})
+	if got == nil || got.Name != "db" {
+		t.Fatalf("expected command-only container, got %+v", got)
+	}
+}
+
+func TestContainerRuntime(t *testing.T) {
+	stubContainerRuntime(t, "true")
+
+	if path, err := ContainerRuntime(config.ContainerTarget{Name: "x"}); err != nil || path != "/usr/bin/podman" {
+		t.Fatalf("expected podman fallback, got %q, %v", path, err)
+	}
+	if _, err := ContainerRuntime(config.ContainerTarget{Name: "x", Runtime: "docker"}); !errors.Is(err, errExecutableNotFound) {
+		t.Fatalf("expected missing docker, got %v", err)
+	}
+	if _, err := ContainerRuntime(config.ContainerTarget{Name: "x", Runtime: "lxc"}); err == nil {
+		t.Fatal("expected unknown runtime to be rejected")
+	}
+}
+
+func TestCheckContainer(t *testing.T) {
+	target := config.ContainerTarget{Name: "devbox"}
+
+	stubContainerRuntime(t, "true")
+	if err := CheckContainer(target); err != nil {
+		t.Fatalf("expected running container, got %v", err)
+	}
+	stubContainerRuntime(t, "false")
+	if err := CheckContainer(target); !errors.Is(err, errContainerNotRunning) {
+		t.Fatalf("expected not running, got %v", err)
+	}
+	stubContainerRuntime(t, "")
+	if err := CheckContainer(target); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected missing container, got %v", err)
+	}
+
+	oldTimeout, oldGrace := containerCheckTimeout, timeoutGrace
+	defer func() { containerCheckTimeout, timeoutGrace = oldTimeout, oldGrace }()
+	containerCheckTimeout, timeoutGrace = 100*time.Millisecond, 100*time.Millisecond
+	stubContainerRuntime(t, "hang")
+	start := time.Now()
+	if err := CheckContainer(target); err == nil || !strings.Contains(err.Error(), "did not answer") || time.Since(start) > 3*time.Second {
+		t.Fatalf("expected a hung runtime to time out, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestPrepareCommand_Container(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stubContainerRuntime(t, "true")
+	cfg := config.Config{
+		DefaultShell: "zsh",
+		Container:    &config.ContainerTarget{Name: "devbox", Shell: "bash", User: "dev", Workdir: "/work"},
+		Commands: []config.Command{
+			{Name: "Test", Command: "go test ./...", Env: map[string]string{"CGO_ENABLED": "0"}},
+			{Name: "Web", Command: "npm start", Cwd: "/work/web"},
+		},
+	}
+
+	cmd, _, err := prepareCommand(cfg, "Test", RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/usr/bin/podman", "exec", "-i", "-t", "--user", "dev", "--workdir", "/work", "--env", "CGO_ENABLED", "devbox", "bash", "-lc", "go test ./..."}
+	if strings.Join(cmd.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+	if !slices.Contains(cmd.Env, "CGO_ENABLED=0") {
+		t.Error("expected the value in the runtime's environment")
+	}
+
+	cmd, _, err = prepareCommand(cfg, "Web", RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.Join(cmd.Args, " "), "--workdir /work/web") {
+		t.Errorf("expected command cwd inside the container, got %q", cmd.Args)
+	}
+
+	stdinIsTerminal = func() bool { return false }
+	if cmd, _, _ = prepareCommand(cfg, "Test", RunOptions{}); cmd.Args[1] != "exec" || cmd.Args[2] != "-i" || cmd.Args[3] != "--user" {
+		t.Errorf("expected -i without -t when stdin is not a terminal, got %q", cmd.Args)
+	}
+
+	stubContainerRuntime(t, "false")
+	if _, _, err := prepareCommand(cfg, "Test", RunOptions{}); !errors.Is(err, errContainerNotRunning) {
+		t.Errorf("expected stopped container to fail before running, got %v", err)
+	}
+}
+
+func TestDetachedShellCommand_FollowsTheProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stubContainerRuntime(t, "true")
+
+	cfg := config.Config{DefaultShell: "sh", Container: &config.ContainerTarget{Name: "devbox"}}
+	cmd, err := detachedShellCommand(cfg, CommandSpec{}, RunOptions{}, "git branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/usr/bin/podman", "exec", "devbox", "sh", "-c", "git branch"}
+	if strings.Join(cmd.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+
+	cfg = config.Config{DefaultShell: "sh", Hosts: []string{"web1"}}
+	if cmd, err = detachedShellCommand(cfg, CommandSpec{}, RunOptions{}, "uptime"); err != nil || cmd.Args[0] != "ssh" || cmd.Args[1] != "-n" {
+		t.Errorf("expected ssh -n to web1, got %q (%v)", cmd.Args, err)
+	}
+	if cmd.Stdin != nil {
+		t.Error("expected no stdin for a helper command")
+	}
+}
@@ -0,0 +1,358 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// Outcomes of a doctor check, from best to worst.
+const (
+	DoctorPass = "pass"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+)
+
+// DoctorSetup is the scope of checks that are not about one profile.
+const DoctorSetup = "setup"
+
+// DoctorCheck is one line of the `drako doctor` report.
+type DoctorCheck struct {
+	Scope  string `json:"scope"` // DoctorSetup or the name of a profile
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// DoctorReport is the outcome of Diagnose.
+type DoctorReport struct {
+	Checks []DoctorCheck `json:"checks"`
+	Passed int           `json:"passed"`
+	Warned int           `json:"warned"`
+	Failed int           `json:"failed"`
+}
+
+func (r *DoctorReport) add(scope, name, status, detail string) {
+	r.Checks = append(r.Checks, DoctorCheck{Scope: scope, Name: name, Status: status, Detail: detail})
+	switch status {
+	case DoctorPass:
+		r.Passed++
+	case DoctorWarn:
+		r.Warned++
+	default:
+		r.Failed++
+	}
+}
+
+// Diagnose checks the setup in configDir: config.toml, the shell, clipboard and git, themes,
+// the pivot file and every equipped profile, including the binaries its commands call and the
+// assets it declares. clipboard names the clipboard tool the TUI would use ("" if none); the
+// UI owns that lookup.
+func Diagnose(configDir, clipboard string) DoctorReport {
+	var r DoctorReport
+
+	base := config.Config{}
+	configPath := filepath.Join(configDir, "config.toml")
+	if data, err := os.ReadFile(configPath); errors.Is(err, os.ErrNotExist) {
+		r.add(DoctorSetup, "config.toml", DoctorWarn, "not created yet; drako sets it up on first start")
+	} else if err != nil {
+		r.add(DoctorSetup, "config.toml", DoctorFail, err.Error())
+	} else {
+		var settings config.AppSettings
+		if _, err := toml.Decode(os.ExpandEnv(string(data)), &settings); err != nil {
+			r.add(DoctorSetup, "config.toml", DoctorFail, err.Error())
+		} else {
+			r.add(DoctorSetup, "config.toml", DoctorPass, configPath)
+			base.DefaultShell, base.Theme = settings.DefaultShell, settings.Theme
+		}
+	}
+	base.ApplyDefaults()
+
+	diagnoseShell(&r, DoctorSetup, base.DefaultShell)
+
+	if clipboard == "" {
+		r.add(DoctorSetup, "clipboard", DoctorWarn, "no clipboard tool found (wl-copy, xclip or xsel on Linux); copying relies on the terminal (OSC 52)")
+	} else {
+		r.add(DoctorSetup, "clipboard", DoctorPass, clipboard)
+	}
+
+	if path, err := lookPathFn("git"); err != nil {
+		r.add(DoctorSetup, "git", DoctorWarn, "not on PATH; summon cannot clone decks")
+	} else {
+		r.add(DoctorSetup, "git", DoctorPass, path)
+	}
+
+	if path, err := config.ThemesPath(); err != nil {
+		r.add(DoctorSetup, "themes", DoctorFail, err.Error())
+	} else if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		r.add(DoctorSetup, "themes", DoctorPass, "built-in themes")
+	} else if themes, err := config.ParseThemesFile(path); err != nil {
+		r.add(DoctorSetup, "themes", DoctorFail, fmt.Sprintf("%s: %v", path, err))
+	} else {
+		r.add(DoctorSetup, "themes", DoctorPass, fmt.Sprintf("%d themes in %s", len(themes), path))
+	}
+	diagnoseTheme(&r, DoctorSetup, base.Theme)
+
+	profiles, broken := config.DiscoverProfilesWithErrors(configDir)
+
+	if pf, err := config.ReadPivotProfile(configDir); err != nil {
+		r.add(DoctorSetup, "pivot", DoctorFail, err.Error())
+	} else if locked := strings.TrimSpace(pf.Locked); locked != "" && !slices.ContainsFunc(profiles, func(p config.ProfileInfo) bool {
+		return config.NormalizeProfileName(p.Name) == config.NormalizeProfileName(locked)
+	}) {
+		r.add(DoctorSetup, "pivot", DoctorWarn, fmt.Sprintf("locked profile %s is not equipped", locked))
+	} else if locked != "" {
+		r.add(DoctorSetup, "pivot", DoctorPass, "locked to "+locked)
+	} else {
+		r.add(DoctorSetup, "pivot", DoctorPass, "no profile locked")
+	}
+
+	if len(profiles) == 0 && len(broken) == 0 {
+		r.add(DoctorSetup, "profiles", DoctorWarn, "no profiles equipped in "+configDir)
+	}
+	for _, b := range broken {
+		r.add(b.Name, "profile", DoctorFail, b.Err)
+	}
+	for _, p := range profiles {
+		diagnoseProfile(&r, configDir, base, p)
+	}
+	return r
+}
+
+func diagnoseProfile(r *DoctorReport, configDir string, base config.Config, p config.ProfileInfo) {
+	cfg := config.ApplyProfileOverlay(base, p.Profile)
+	if err := config.ValidateConfig(cfg); err != nil {
+		r.add(p.Name, "grid", DoctorFail, err.Error())
+	} else {
+		r.add(p.Name, "grid", DoctorPass, fmt.Sprintf("%d cells on %dx%d", len(cfg.Commands), cfg.X, cfg.Y))
+	}
+	if p.Profile.Shell != nil {
+		diagnoseShell(r, p.Name, cfg.DefaultShell)
+	}
+	if strings.TrimSpace(p.Profile.Theme) != "" {
+		diagnoseTheme(r, p.Name, p.Profile.Theme)
+	}
+
+	if p.Profile.Assets != nil && len(*p.Profile.Assets) > 0 {
+		dir := filepath.Join(configDir, "assets", p.Name)
+		var missing []string
+		for _, rel := range *p.Profile.Assets {
+			rel = strings.TrimPrefix(filepath.Clean(strings.TrimSpace(rel)), "./")
+			if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+				missing = append(missing, rel)
+			}
+		}
+		if len(missing) > 0 {
+			r.add(p.Name, "assets", DoctorFail, fmt.Sprintf("missing in %s: %s", dir, strings.Join(missing, ", ")))
+		} else {
+			r.add(p.Name, "assets", DoctorPass, fmt.Sprintf("%d declared, all in %s", len(*p.Profile.Assets), dir))
+		}
+	}
+
+	switch {
+	case len(cfg.Hosts) > 0:
+		r.add(p.Name, "binaries", DoctorPass, "not checked; commands run on "+strings.Join(cfg.Hosts, ", "))
+		return
+	case cfg.Container != nil:
+		r.add(p.Name, "binaries", DoctorPass, "not checked; commands run in container "+cfg.Container.Name)
+		return
+	}
+	switch cfg.DefaultShell {
+	case "pwsh", "powershell", "cmd", "cmd.exe":
+		r.add(p.Name, "binaries", DoctorPass, "not checked for "+cfg.DefaultShell)
+		return
+	}
+
+	for _, check := range PendingRequirementChecks(cfg) {
+		RunRequirementCheck(cfg, check)
+	}
+	found, skipped, warned := map[string]bool{}, 0, false
+	for _, c := range ApplyRequirements(cfg) {
+		if c.Unmet != "" {
+			// Greyed out or hidden anyway.
+			skipped++
+			continue
+		}
+		if c.Container != nil {
+			continue
+		}
+		var missing []string
+		for _, bin := range cellBinaries(c) {
+			if _, err := lookPathFn(bin); err != nil {
+				missing = append(missing, bin)
+			} else {
+				found[bin] = true
+			}
+		}
+		if len(missing) > 0 {
+			r.add(p.Name, "binaries", DoctorWarn, fmt.Sprintf("%s: %s not on PATH", strings.TrimSpace(c.Name), strings.Join(missing, ", ")))
+			warned = true
+		}
+	}
+	if warned {
+		return
+	}
+	detail := fmt.Sprintf("%d found on PATH", len(found))
+	if skipped > 0 {
+		detail += fmt.Sprintf(", %d unavailable cells skipped", skipped)
+	}
+	r.add(p.Name, "binaries", DoctorPass, detail)
+}
+
+func diagnoseShell(r *DoctorReport, scope, shell string) {
+	bin := buildShellCmd(shell, "").Args[0]
+	path, err := lookPathFn(bin)
+	switch {
+	case err != nil:
+		r.add(scope, "shell", DoctorFail, fmt.Sprintf("%s (%s) is not on PATH", shell, bin))
+	case bin == "bash" && shell != "bash":
+		r.add(scope, "shell", DoctorWarn, fmt.Sprintf("unknown shell %q, bash is used (%s)", shell, path))
+	default:
+		r.add(scope, "shell", DoctorPass, fmt.Sprintf("%s (%s)", shell, path))
+	}
+}
+
+func diagnoseTheme(r *DoctorReport, scope, theme string) {
+	if theme = strings.TrimSpace(theme); theme != "" && !config.HasTheme(theme) {
+		r.add(scope, "theme", DoctorWarn, fmt.Sprintf("theme %q not found, dracula is used", theme))
+	}
+}
+
+// cellBinaries lists the executables the commands of a cell call, in order and without duplicates.
+func cellBinaries(c config.Command) []string {
+	commands := []string{c.Command, c.ItemsCommand, c.ItemCommand, c.StatusCommand}
+	for _, s := range c.Steps {
+		commands = append(commands, s.Command)
+	}
+	for _, it := range c.Items {
+		if it.Unmet != "" || it.Container != nil {
+			continue
+		}
+		commands = append(commands, it.Command)
+		for _, s := range it.Steps {
+			commands = append(commands, s.Command)
+		}
+	}
+	var bins []string
+	for _, command := range commands {
+		for _, bin := range CommandBinaries(command) {
+			if !slices.Contains(bins, bin) {
+				bins = append(bins, bin)
+			}
+		}
+	}
+	return bins
+}
+
+var (
+	// Redirections such as 2>&1 or &>, which must not read as command separators.
+	redirectRe = regexp.MustCompile(`\d*>&\d*-?|&>`)
+	// Separators after which a new command starts.
+	separatorRe = regexp.MustCompile("&&|\\|\\||[|;&\n`]|\\$\\(|\\(")
+	// A plain program name: no quotes, variables, placeholders, globs or paths.
+	binaryNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._+-]*$`)
+)
+
+// shellWords are keywords and builtins that are not looked up on PATH.
+var shellWords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true, "case": true, "esac": true,
+	"for": true, "while": true, "until": true, "do": true, "done": true, "in": true, "function": true,
+	"select": true, "time": true, "cd": true, "echo": true, "printf": true, "read": true, "export": true,
+	"source": true, "set": true, "unset": true, "local": true, "return": true, "exit": true, "eval": true,
+	"alias": true, "true": true, "false": true, "test": true, "shift": true, "trap": true, "wait": true,
+	"type": true, "builtin": true, "declare": true, "typeset": true, "let": true, "pushd": true,
+	"popd": true, "ulimit": true, "umask": true, "hash": true, "jobs": true, "fg": true, "bg": true,
+	"kill": true, "break": true, "continue": true, "exec": true, "command": true,
+}
+
+// commandPrefixes run the command that follows them.
+var commandPrefixes = map[string]bool{
+	"sudo": true, "doas": true, "env": true, "nohup": true, "nice": true, "exec": true, "command": true,
+	"time": true, "if": true, "elif": true, "while": true, "until": true, "then": true, "else": true,
+	"do": true, "!": true, "{": true,
+}
+
+// CommandBinaries makes a best guess at the programs a shell command line runs: the first word
+// of each command in it, past assignments, sudo and similar prefixes. Keywords, builtins,
+// paths and anything with quotes, variables or {{placeholders}} are left out.
+func CommandBinaries(command string) []string {
+	command = redirectRe.ReplaceAllString(stripQuotesAndComments(command), " ")
+	var bins []string
+	for _, segment := range separatorRe.Split(command, -1) {
+		words := strings.Fields(segment)
+		for i := 0; i < len(words); i++ {
+			w := words[i]
+			switch {
+			case w == "case", w == "function":
+				// The rest is patterns and names rather than commands.
+			case strings.Contains(w, "=") && !strings.HasPrefix(w, "="),
+				strings.HasSuffix(w, ")"):
+				// VAR=value prefix or a case pattern; the command follows.
+				continue
+			case w == "command" && i+1 < len(words) && (words[i+1] == "-v" || words[i+1] == "-V"):
+				// An existence check, which is what the command does instead of failing.
+			case commandPrefixes[w]:
+				if binaryNameRe.MatchString(w) && !shellWords[w] && !slices.Contains(bins, w) {
+					bins = append(bins, w)
+				}
+				// Skip the prefix's own flags.
+				for i+1 < len(words) && strings.HasPrefix(words[i+1], "-") {
+					i++
+				}
+				continue
+			case binaryNameRe.MatchString(w) && !shellWords[w]:
+				if !slices.Contains(bins, w) {
+					bins = append(bins, w)
+				}
+			}
+			break
+		}
+	}
+	return bins
+}
+
+// stripQuotesAndComments blanks out quoted strings and drops comments, so that their text
+// does not read as commands.
+func stripQuotesAndComments(s string) string {
+	var b strings.Builder
+	var quote rune
+	atWordStart := true
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+				b.WriteString("''")
+			}
+			continue
+		case c == '\\':
+			// Keep the escaped character out of the quote and comment handling.
+			b.WriteRune(c)
+			if i+1 < len(runes) {
+				i++
+				b.WriteRune(runes[i])
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && atWordStart:
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+		default:
+			b.WriteRune(c)
+		}
+		atWordStart = strings.ContainsRune(" \t\n;|&(", c)
+	}
+	return b.String()
+}
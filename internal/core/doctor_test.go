@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandBinaries(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"sudo apt update && sudo apt upgrade", []string{"sudo", "apt"}},
+		{"FOO=1 sudo -E make build 2>&1 | tee log.txt; echo done", []string{"sudo", "make", "tee"}},
+		{"if ! command -v fzf &> /dev/null; then\n  echo \"missing (install it)\" # (see docs)\n  exit 1\nfi\nfzf", []string{"fzf"}},
+		{"for f in *.go; do gofmt -l $f; done", []string{"gofmt"}},
+		{"echo $(date +%s) `hostname`", []string{"date", "hostname"}},
+		{"git switch {{item}}", []string{"git"}},
+		{"$EDITOR ~/.bashrc && ./run.sh && ~/bin/tool", nil},
+	}
+	for _, tt := range tests {
+		if got := CommandBinaries(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CommandBinaries(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	configDir := filepath.Join(xdg, "drako")
+
+	oldLookPath := lookPathFn
+	defer func() { lookPathFn = oldLookPath }()
+	lookPathFn = func(name string) (string, error) {
+		switch name {
+		case "bash", "git", "ls":
+			return "/usr/bin/" + name, nil
+		}
+		return "", fmt.Errorf("%s not found", name)
+	}
+
+	write := func(rel, content string) {
+		path := filepath.Join(configDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("config.toml", "default_shell = \"bash\"\n")
+	write("pivot.toml", "locked = \"gone\"\n")
+	write("ops.profile.toml", `x = 2
+y = 1
+theme = "no-such-theme"
+assets = ["scripts/deploy.sh", "missing.txt"]
+
+[[commands]]
+name = "List"
+command = "ls -la | less"
+col = "a"
+row = 0
+
+[[commands]]
+name = "Mac Only"
+command = "brew upgrade"
+col = "b"
+row = 0
+requires = { os = ["no-such-os"] }
+`)
+	write("assets/ops/scripts/deploy.sh", "#!/bin/sh\n")
+	write("broken.profile.toml", "x = [\n")
+
+	report := Diagnose(configDir, "")
+	status := func(scope, name string) (string, string) {
+		for _, c := range report.Checks {
+			if c.Scope == scope && c.Name == name {
+				return c.Status, c.Detail
+			}
+		}
+		return "", ""
+	}
+	tests := []struct {
+		scope, name, status, detail string
+	}{
+		{DoctorSetup, "config.toml", DoctorPass, ""},
+		{DoctorSetup, "shell", DoctorPass, "/usr/bin/bash"},
+		{DoctorSetup, "clipboard", DoctorWarn, ""},
+		{DoctorSetup, "git", DoctorPass, ""},
+		{DoctorSetup, "themes", DoctorPass, ""},
+		{DoctorSetup, "pivot", DoctorWarn, "gone"},
+		{"broken", "profile", DoctorFail, ""},
+		{"ops", "grid", DoctorPass, "2 cells"},
+		{"ops", "theme", DoctorWarn, "no-such-theme"},
+		{"ops", "assets", DoctorFail, "missing.txt"},
+		{"ops", "binaries", DoctorWarn, "List: less not on PATH"},
+	}
+	for _, tt := range tests {
+		got, detail := status(tt.scope, tt.name)
+		if got != tt.status || !strings.Contains(detail, tt.detail) {
+			t.Errorf("%s/%s: expected %s containing %q, got %s %q", tt.scope, tt.name, tt.status, tt.detail, got, detail)
+		}
+	}
+	if report.Failed != 2 || report.Passed+report.Warned+report.Failed != len(report.Checks) {
+		t.Errorf("unexpected totals %d/%d/%d for %d checks", report.Passed, report.Warned, report.Failed, len(report.Checks))
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// DryRunProcess is one process a command would start.
+type DryRunProcess struct {
+	Step string   // Step name, for multi-step commands
+	Argv []string // Exactly what would be executed
+	Dir  string
+}
+
+// DryRunReport is what RunCommand would do for a cell, fully resolved but not executed.
+type DryRunReport struct {
+	Name      string
+	Processes []DryRunProcess
+	Launcher  string
+	Timeout   time.Duration
+	Env       []string          // The complete environment, after the env policy
+	Set       map[string]string // Variables drako adds or changes (built-ins, env_file, env)
+	Removed   []string          // Inherited variables the env policy filters out
+}
+
+// DryRun resolves the selected cell the way RunCommandWith would — parameters, built-ins,
+// shell argv, cwd, environment, launcher and timeout — without starting anything.
+func DryRun(cfg config.Config, selected string, opts RunOptions) (DryRunReport, error) {
+	report := DryRunReport{Name: selected, Launcher: LauncherInline}
+	if opts.Parent == "" && strings.HasPrefix(selected, "drako ") {
+		report.Launcher = "internal"
+		report.Processes = []DryRunProcess{{Argv: strings.Fields(selected)}}
+		return report, nil
+	}
+
+	var cmds []*exec.Cmd
+	spec := CommandSpec{Name: selected}
+	if parent, item, found := FindCommandIn(cfg, opts.Parent, selected); found && opts.Replay == nil {
+		spec = ResolveCommandSpec(parent, item)
+	}
+	if len(spec.Steps) > 0 {
+		values, err := commandValues(spec, opts)
+		if err != nil {
+			return report, err
+		}
+		for i, step := range spec.Steps {
+			cmd, err := prepareStep(cfg, spec, step, opts, values)
+			if err != nil {
+				return report, fmt.Errorf("step %s: %w", StepName(step, i), err)
+			}
+			cmds = append(cmds, cmd)
+			report.Processes = append(report.Processes, DryRunProcess{Step: StepName(step, i)})
+		}
+	} else {
+		cmd, resolved, err := prepareCommand(cfg, selected, opts)
+		if err != nil {
+			return report, err
+		}
+		spec = resolved
+		cmds = append(cmds, cmd)
+		report.Processes = append(report.Processes, DryRunProcess{})
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return report, err
+	}
+	for i, cmd := range cmds {
+		report.Processes[i].Argv = cmd.Args
+		report.Processes[i].Dir = cmd.Dir
+		if cmd.Dir == "" {
+			report.Processes[i].Dir = cwd
+		}
+	}
+
+	if opts.Replay == nil && len(spec.Steps) == 0 {
+		launcher, err := EffectiveLauncher(cfg, spec)
+		if err != nil {
+			return report, err
+		}
+		if launcher != LauncherInline && !LauncherAvailable(launcher) {
+			launcher = LauncherInline
+		}
+		report.Launcher = launcher
+	}
+	if report.Timeout, err = CommandTimeout(cfg, spec); err != nil {
+		return report, err
+	}
+
+	report.Env = cmds[0].Env
+	if report.Env == nil {
+		report.Env = os.Environ()
+	}
+	report.Set, report.Removed = envChanges(os.Environ(), report.Env)
+	return report, nil
+}
+
+// envChanges compares the inherited environment with the one a command gets.
+func envChanges(inherited, env []string) (map[string]string, []string) {
+	before := make(map[string]string, len(inherited))
+	for _, e := range inherited {
+		k, v, _ := strings.Cut(e, "=")
+		before[k] = v
+	}
+	after := make(map[string]string, len(env))
+	set := make(map[string]string)
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		after[k] = v
+		if old, ok := before[k]; !ok || old != v {
+			set[k] = v
+		}
+	}
+	var removed []string
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(removed)
+	return set, removed
+}
+
+// FormatDryRun renders a report for the terminal. With fullEnv the complete environment is
+// listed; otherwise only what drako changes.
+func FormatDryRun(r DryRunReport, fullEnv bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- Dry Run: %s ---\n", r.Name)
+	for i, p := range r.Processes {
+		if p.Step != "" {
+			fmt.Fprintf(&b, "\nStep %d/%d: %s\n", i+1, len(r.Processes), p.Step)
+		}
+		fmt.Fprintf(&b, "Exec:     %s\n", FormatArgv(p.Argv))
+		if p.Dir != "" {
+			fmt.Fprintf(&b, "CWD:      %s\n", p.Dir)
+		}
+	}
+	if len(r.Processes) > 1 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Launcher: %s\n", r.Launcher)
+	if r.Timeout > 0 {
+		fmt.Fprintf(&b, "Timeout:  %s\n", r.Timeout)
+	}
+
+	if fullEnv {
+		fmt.Fprintf(&b, "\nEnvironment (%d variables):\n", len(r.Env))
+		env := append([]string(nil), r.Env...)
+		sort.Strings(env)
+		for _, e := range env {
+			fmt.Fprintf(&b, "  %s\n", e)
+		}
+	} else {
+		keys := make([]string, 0, len(r.Set))
+		for k := range r.Set {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(&b, "\nEnvironment: %d variables, %d set by drako, %d filtered out\n", len(r.Env), len(keys), len(r.Removed))
+		for _, k := range keys {
+			fmt.Fprintf(&b, "  %s=%s\n", k, r.Set[k])
+		}
+	}
+	if len(r.Removed) > 0 {
+		fmt.Fprintf(&b, "Filtered: %s\n", strings.Join(r.Removed, " "))
+	}
+	return b.String()
+}
+
+// FormatArgv joins argv for display, quoting the arguments that need it.
+func FormatArgv(argv []string) string {
+	words := make([]string, len(argv))
+	for i, a := range argv {
+		words[i] = a
+		if a == "" || strings.ContainsAny(a, " \t\n'\"\\$`|&;<>(){}*?#~") {
+			if q, err := QuoteForShell("sh", a); err == nil {
+				words[i] = q
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestDryRun_ResolvesWithoutExecuting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SECRET_TOKEN", "hunter2")
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		EnvBlocklist: []string{"SECRET_*"},
+		Timeout:      "5m",
+		Commands: []config.Command{
+			{
+				Name:    "Touch",
+				Command: "touch {{file}}",
+				Cwd:     dir,
+				Env:     map[string]string{"MODE": "test"},
+				Params:  []config.CommandParam{{Name: "file"}},
+			},
+			{
+				Name: "Ship",
+				Steps: []config.CommandStep{
+					{Name: "build", Command: "make"},
+					{Command: "make install", Cwd: "sub"},
+				},
+			},
+		},
+	}
+
+	opts := RunOptions{Profile: "dev", Params: map[string]string{"file": marker}}
+	r, err := DryRun(cfg, "Touch", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Processes) != 1 {
+		t.Fatalf("expected one process, got %+v", r.Processes)
+	}
+	p := r.Processes[0]
+	if strings.Join(p.Argv, "|") != "sh|-c|touch '"+marker+"'" || p.Dir != dir {
+		t.Fatalf("unexpected process %+v", p)
+	}
+	if r.Set["MODE"] != "test" || r.Set["DRAKO_PROFILE"] != "dev" {
+		t.Errorf("expected env and built-ins to be listed as set, got %v", r.Set)
+	}
+	if len(r.Removed) != 1 || r.Removed[0] != "SECRET_TOKEN" {
+		t.Errorf("expected the blocklisted variable to be filtered, got %v", r.Removed)
+	}
+	if r.Launcher != LauncherInline || r.Timeout.String() != "5m0s" {
+		t.Errorf("unexpected launcher/timeout: %s %s", r.Launcher, r.Timeout)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatal("dry run must not execute the command")
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	r, err = DryRun(cfg, "Ship", RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Processes) != 2 || r.Processes[0].Step != "build" || r.Processes[1].Step != "step 2" || r.Processes[1].Dir != filepath.Join(dir, "sub") {
+		t.Fatalf("unexpected steps: %+v", r.Processes)
+	}
+
+	out := FormatDryRun(r, false)
+	if !strings.Contains(out, "Step 2/2: step 2") || !strings.Contains(out, "Exec:     sh -c 'make install'") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
+
+func TestRunCommand_DryRunDoesNotExecute(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "ran")
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	paused := false
+	pauseFn = func(string) { paused = true }
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands:     []config.Command{{Name: "Touch", Command: "touch " + marker}},
+	}
+	RunCommandWith(cfg, "Touch", RunOptions{DryRun: true})
+
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatal("dry run must not execute the command")
+	}
+	if !paused {
+		t.Error("expected the report to wait for a key")
+	}
+	if entries, _ := ReadHistory(HistoryFilter{}); len(entries) != 0 {
+		t.Errorf("dry runs must not be recorded, got %d entries", len(entries))
+	}
+}
@@ -1,15 +1,22 @@
 package core
 
 import (
+	"bufio"
+	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/lucky7xz/drako/internal/config"
 )
 
 // PrepareEnv returns the environment variables to use for command execution.
-// If whitelist is empty, it returns the original environment (pass-through).
-// If whitelist is set, it returns only the variables that match the whitelist.
-func PrepareEnv(env []string, whitelist []string) []string {
-	if len(whitelist) == 0 {
+// If whitelist is empty, every variable passes; otherwise only variables matching the whitelist do.
+// Variables matching the blocklist are always removed, even if whitelisted.
+// Both lists use shell-glob patterns (e.g. "AWS_*").
+func PrepareEnv(env []string, whitelist []string, blocklist []string) []string {
+	if len(whitelist) == 0 && len(blocklist) == 0 {
 		return env
 	}
 
@@ -21,28 +28,129 @@ func PrepareEnv(env []string, whitelist []string) []string {
 		}
 		key := pair[0]
 
-		// Check if key matches any pattern in whitelist
-		matched := false
-		for _, pattern := range whitelist {
-			// Simple exact match or basic wildcard handling could go here.
-			// For now, let's do exact match (case-sensitive or insensitive? usually env vars are case-sensitive on Linux)
-			// but let's allow simple globs if needed later. For now, exact match + simple suffix?
-			// Let's stick to exact match or shell-glob style Match.
+		if len(whitelist) > 0 && !matchesEnvPattern(key, whitelist) {
+			continue
+		}
+		if matchesEnvPattern(key, blocklist) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
 
-			if match, _ := filepath.Match(pattern, key); match {
-				matched = true
-				break
-			}
-			// Fallback for exact string match if filepath.Match is too picky about slashes (though for env keys it's fine)
-			if key == pattern {
-				matched = true
-				break
-			}
+// matchesEnvPattern reports whether key matches any of the patterns.
+func matchesEnvPattern(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// Env keys have no path separators, so filepath.Match gives plain shell-glob semantics.
+		if match, _ := filepath.Match(pattern, key); match {
+			return true
+		}
+		// Fallback for exact string match if the pattern is malformed as a glob
+		if key == pattern {
+			return true
 		}
+	}
+	return false
+}
 
-		if matched {
-			filtered = append(filtered, e)
+// MergeEnv sets the given variables on top of env, replacing existing values.
+// Keys are applied in sorted order so the result is deterministic.
+func MergeEnv(env []string, vars map[string]string) []string {
+	if len(vars) == 0 {
+		return env
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, 0, len(env)+len(vars))
+	for _, e := range env {
+		key, _, _ := strings.Cut(e, "=")
+		if _, override := vars[key]; override {
+			continue
 		}
+		out = append(out, e)
 	}
-	return filtered
+	for _, k := range keys {
+		out = append(out, k+"="+vars[k])
+	}
+	return out
+}
+
+// CommandEnv builds the environment for a command from the current process environment
+// and the effective config: the global whitelist/blocklist, the profile's own whitelist,
+// and finally the profile's explicit env values.
+func CommandEnv(cfg config.Config) []string {
+	env := PrepareEnv(os.Environ(), cfg.EnvWhitelist, cfg.EnvBlocklist)
+	if len(cfg.ProfileEnvWhitelist) > 0 {
+		// A profile whitelist can only narrow what the global settings let through.
+		env = PrepareEnv(env, cfg.ProfileEnvWhitelist, nil)
+	}
+	return MergeEnv(env, cfg.Env)
+}
+
+// LoadEnvFile reads KEY=VALUE pairs from a dotenv-style file.
+// Blank lines and # comments are skipped, an optional "export " prefix is accepted and
+// values may be wrapped in single or double quotes.
+func LoadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// CommandEnvOverrides returns the variables a command sets on top of the inherited
+// environment: its env_file first, then its env table, which wins on conflicts.
+// A relative env_file is resolved against base, the command's working directory.
+func CommandEnvOverrides(spec CommandSpec, base, profile string) (map[string]string, error) {
+	vars := make(map[string]string)
+	if strings.TrimSpace(spec.EnvFile) != "" {
+		path, err := ResolveCommandPath(spec.EnvFile, base, profile)
+		if err != nil {
+			return nil, fmt.Errorf("env_file %q: %w", spec.EnvFile, err)
+		}
+		fileVars, err := LoadEnvFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("env_file: %w", err)
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+	for k, v := range spec.Env {
+		vars[k] = v
+	}
+	return vars, nil
 }
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestPrepareEnv(t *testing.T) {
+	env := []string{"PATH=/bin", "HOME=/home/u", "AWS_KEY=x", "AWS_REGION=eu", "GITHUB_TOKEN=t", "broken"}
+
+	tests := []struct {
+		name      string
+		whitelist []string
+		blocklist []string
+		want      []string
+	}{
+		{"pass-through", nil, nil, env},
+		{"whitelist", []string{"PATH", "AWS_*"}, nil, []string{"PATH=/bin", "AWS_KEY=x", "AWS_REGION=eu"}},
+		{"blocklist glob", nil, []string{"AWS_*", "GITHUB_TOKEN"}, []string{"PATH=/bin", "HOME=/home/u"}},
+		{"blocklist wins over whitelist", []string{"PATH", "AWS_*"}, []string{"AWS_KEY"}, []string{"PATH=/bin", "AWS_REGION=eu"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := PrepareEnv(env, tc.whitelist, tc.blocklist)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCommandEnv_ProfilePolicy(t *testing.T) {
+	t.Setenv("DRAKO_TEST_KEEP", "1")
+	t.Setenv("DRAKO_TEST_SECRET", "s")
+	t.Setenv("DRAKO_TEST_OTHER", "o")
+
+	cfg := config.Config{
+		EnvWhitelist:        []string{"DRAKO_TEST_*"},
+		EnvBlocklist:        []string{"DRAKO_TEST_SECRET"},
+		ProfileEnvWhitelist: []string{"DRAKO_TEST_KEEP", "DRAKO_TEST_SECRET"},
+		Env:                 map[string]string{"DRAKO_TEST_SET": "v", "DRAKO_TEST_KEEP": "2"},
+	}
+	got := CommandEnv(cfg)
+	want := []string{"DRAKO_TEST_KEEP=2", "DRAKO_TEST_SET=v"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# comment\n\nexport API_URL=https://example.com\nNAME=\"drako cli\"\nEMPTY=\nQUOTED='a=b'\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatalf("LoadEnvFile failed: %v", err)
+	}
+	want := map[string]string{"API_URL": "https://example.com", "NAME": "drako cli", "EMPTY": "", "QUOTED": "a=b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("OK=1\nnot a pair\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEnvFile(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Fatalf("expected line number in error, got %v", err)
+	}
+}
+
+func TestCommandEnvOverrides_EnvWinsOverFile(t *testing.T) {
+	base := t.TempDir()
+	if err := os.WriteFile(filepath.Join(base, "app.env"), []byte("A=file\nB=file\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec := CommandSpec{EnvFile: "app.env", Env: map[string]string{"B": "table"}}
+
+	got, err := CommandEnvOverrides(spec, base, "core")
+	if err != nil {
+		t.Fatalf("CommandEnvOverrides failed: %v", err)
+	}
+	want := map[string]string{"A": "file", "B": "table"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if _, err := CommandEnvOverrides(CommandSpec{EnvFile: "missing.env"}, base, "core"); err == nil {
+		t.Fatal("expected error for missing env_file")
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// RunResult is the outcome of a headless run, as printed by `drako run --json`.
+type RunResult struct {
+	Profile    string      `json:"profile"`
+	Cell       string      `json:"cell"`
+	ExitCode   int         `json:"exit_code"` // -1 if the process never reported one
+	Error      string      `json:"error,omitempty"`
+	TimedOut   bool        `json:"timed_out,omitempty"`
+	Start      time.Time   `json:"start"`
+	End        time.Time   `json:"end"`
+	DurationMS int64       `json:"duration_ms"`
+	Steps      []RunResult `json:"steps,omitempty"` // One result per step of a multi-step command
+}
+
+// Succeeded reports whether the run exited cleanly.
+func (r RunResult) Succeeded() bool {
+	return r.ExitCode == 0 && r.Error == ""
+}
+
+// RunHeadless executes the selected cell without the TUI and reports how it went. The command
+// always runs inline in the foreground with stdin attached and its output written to out; launchers,
+// background mode, debug capture and pauses do not apply. Steps stop at the first failure unless
+// they continue_on_error. Every process is recorded in history. A non-nil error means nothing
+// could be started. An item is looked up in the cell named by opts.Parent.
+func RunHeadless(cfg config.Config, selected string, opts RunOptions, out io.Writer) (RunResult, error) {
+	result := RunResult{Profile: opts.Profile, Cell: selected, Start: time.Now()}
+
+	spec := CommandSpec{Name: selected}
+	if parent, item, found := FindCommandIn(cfg, opts.Parent, selected); found {
+		spec = ResolveCommandSpec(parent, item)
+	}
+	timeout, err := CommandTimeout(cfg, spec)
+	if err != nil {
+		return result, err
+	}
+
+	if len(spec.Steps) == 0 {
+		cmd, resolved, err := prepareCommand(cfg, selected, opts)
+		if err != nil {
+			return result, err
+		}
+		result = runHeadlessProcess(cmd, newHistoryEntry(cfg, opts, resolved, cmd, ModeLive), timeout, out)
+		result.Cell = selected
+		return result, nil
+	}
+
+	values, err := commandValues(spec, opts)
+	if err != nil {
+		return result, err
+	}
+	stopped := false
+	for i, step := range spec.Steps {
+		if stopped {
+			break
+		}
+		cmd, err := prepareStep(cfg, spec, step, opts, values)
+		if err != nil {
+			result.Steps = append(result.Steps, RunResult{Profile: opts.Profile, Cell: StepName(step, i), ExitCode: -1, Error: err.Error()})
+		} else {
+			entry := newHistoryEntry(cfg, opts, spec, cmd, ModeLive)
+			entry.Step = StepName(step, i)
+			r := runHeadlessProcess(cmd, entry, timeout, out)
+			r.Cell = entry.Step
+			result.Steps = append(result.Steps, r)
+		}
+		last := result.Steps[len(result.Steps)-1]
+		if !last.Succeeded() {
+			if result.Succeeded() {
+				// The first failure decides the exit code of the whole run.
+				result.ExitCode, result.Error, result.TimedOut = last.ExitCode, last.Error, last.TimedOut
+			}
+			stopped = !step.ContinueOnError
+		}
+	}
+	result.End = time.Now()
+	result.DurationMS = result.End.Sub(result.Start).Milliseconds()
+	return result, nil
+}
+
+// runHeadlessProcess runs one prepared process and records it in history.
+func runHeadlessProcess(cmd *exec.Cmd, entry HistoryEntry, timeout time.Duration, out io.Writer) RunResult {
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	err := runWithTimeout(cmd, timeout, true)
+	done := finishHistoryEntry(entry, cmd, err)
+	recordHistory(done)
+	return RunResult{
+		Profile:    done.Profile,
+		Cell:       done.Cell,
+		ExitCode:   done.ExitCode,
+		Error:      done.Error,
+		TimedOut:   errors.Is(err, errTimedOut),
+		Start:      done.Start,
+		End:        done.End,
+		DurationMS: done.Duration().Milliseconds(),
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestRunHeadless(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) { t.Error("headless runs must not pause") }
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{
+			{Name: "Greet", Command: "echo hello {{who}}", Params: []config.CommandParam{{Name: "who"}}},
+			{Name: "Check", Command: "exit 3"},
+			{Name: "Slow", Command: "sleep 5", Timeout: "100ms"},
+			{Name: "Deploy", Steps: []config.CommandStep{
+				{Name: "lint", Command: "exit 1", ContinueOnError: true},
+				{Name: "test", Command: "exit 2"},
+				{Name: "publish", Command: "echo published"},
+			}},
+		},
+	}
+
+	var out bytes.Buffer
+	r, err := RunHeadless(cfg, "Greet", RunOptions{Profile: "core", Params: map[string]string{"who": "world"}}, &out)
+	if err != nil || !r.Succeeded() {
+		t.Fatalf("expected Greet to succeed, got %+v, %v", r, err)
+	}
+	if strings.TrimSpace(out.String()) != "hello world" {
+		t.Fatalf("expected the output on out, got %q", out.String())
+	}
+
+	if r, _ := RunHeadless(cfg, "Check", RunOptions{}, &out); r.ExitCode != 3 {
+		t.Fatalf("expected exit code 3, got %+v", r)
+	}
+
+	r, _ = RunHeadless(cfg, "Slow", RunOptions{}, &out)
+	if !r.TimedOut || r.Succeeded() || time.Duration(r.DurationMS)*time.Millisecond > 4*time.Second {
+		t.Fatalf("expected Slow to time out, got %+v", r)
+	}
+
+	out.Reset()
+	r, err = RunHeadless(cfg, "Deploy", RunOptions{}, &out)
+	if err != nil {
+		t.Fatalf("RunHeadless failed: %v", err)
+	}
+	if r.ExitCode != 1 || len(r.Steps) != 2 {
+		t.Fatalf("expected the first failure to decide and publish to be skipped, got %+v", r)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output from skipped steps, got %q", out.String())
+	}
+
+	if _, err := RunHeadless(cfg, "Missing Cell Name", RunOptions{}, &out); err == nil {
+		t.Fatal("expected an error for a cell that cannot be started")
+	}
+
+	got, err := ReadHistory(HistoryFilter{Cell: "Check"})
+	if err != nil || len(got) != 1 || got[0].ExitCode != 3 {
+		t.Fatalf("expected the run to be recorded in history, got %+v, %v", got, err)
+	}
+}
+
+func TestRunHeadless_ItemOfParent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{
+			{Name: "A", Items: []config.CommandItem{{Name: "status", Command: "echo a"}}},
+			{Name: "B", Items: []config.CommandItem{{Name: "status", Command: "echo b"}}},
+		},
+	}
+	var out bytes.Buffer
+	if r, err := RunHeadless(cfg, "status", RunOptions{Parent: "B"}, &out); err != nil || !r.Succeeded() {
+		t.Fatalf("expected B's status to run, got %+v, %v", r, err)
+	}
+	if strings.TrimSpace(out.String()) != "b" {
+		t.Errorf("expected B's item to run, got %q", out.String())
+	}
+}
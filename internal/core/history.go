@@ -0,0 +1,396 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+const (
+	historyFilename = "history.jsonl"
+	// historyMaxBytes caps the size of the history store; pruning keeps the newest half.
+	historyMaxBytes int64 = 2 * 1024 * 1024
+	// historyMaxAge is how long an entry is kept.
+	historyMaxAge = 90 * 24 * time.Hour
+)
+
+// Execution modes recorded in history. Commands handed to a launcher record the launcher name.
+const (
+	ModeLive       = "live"
+	ModeDebug      = "debug"
+	ModeBackground = "background"
+)
+
+// HistoryEntry is one completed execution, stored as a single JSON line.
+type HistoryEntry struct {
+	Profile  string    `json:"profile"`
+	Cell     string    `json:"cell"`
+	Step     string    `json:"step,omitempty"` // Step name, for one step of a multi-step command
+	Argv     []string  `json:"argv"`
+	Cwd      string    `json:"cwd"`
+	Shell    string    `json:"shell"`
+	Mode     string    `json:"mode"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	ExitCode int       `json:"exit_code"`        // -1 if the process never reported one (e.g. failed to start)
+	Error    string    `json:"error,omitempty"`  // Start/wait error, if any
+	Output   string    `json:"output,omitempty"` // Capture file of a debug run
+}
+
+// Duration returns how long the execution took.
+func (e HistoryEntry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Succeeded reports whether the command exited cleanly.
+func (e HistoryEntry) Succeeded() bool {
+	return e.ExitCode == 0 && e.Error == ""
+}
+
+// CommandLine returns the command as the user would type it: the string handed to the
+// shell for configured commands, or the joined argv for direct executions.
+func (e HistoryEntry) CommandLine() string {
+	if e.Shell != "" && len(e.Argv) > 0 {
+		return e.Argv[len(e.Argv)-1]
+	}
+	return strings.Join(e.Argv, " ")
+}
+
+// HistoryFilter narrows ReadHistory results. Zero values match everything.
+type HistoryFilter struct {
+	Profile string    // Exact profile name (case-insensitive)
+	Cell    string    // Substring of the cell name (case-insensitive)
+	Status  string    // "ok" or "fail"
+	Since   time.Time // Only entries that started at or after this time
+	Until   time.Time // Only entries that started before this time
+	Limit   int       // Maximum number of entries returned (0 = no limit)
+}
+
+// Match reports whether an entry passes the filter.
+func (f HistoryFilter) Match(e HistoryEntry) bool {
+	if f.Profile != "" && !strings.EqualFold(f.Profile, e.Profile) {
+		return false
+	}
+	if f.Cell != "" && !strings.Contains(strings.ToLower(e.Cell), strings.ToLower(f.Cell)) {
+		return false
+	}
+	switch f.Status {
+	case "ok":
+		if !e.Succeeded() {
+			return false
+		}
+	case "fail":
+		if e.Succeeded() {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && e.Start.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Start.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ParseHistoryQuery turns a filter string such as "profile:git status:fail date:yesterday deploy"
+// into a HistoryFilter. Recognized keys are profile:, cell:, status: (ok|fail) and
+// date: (today, yesterday, YYYY-MM-DD or a relative window like 7d). Bare words match the cell name.
+func ParseHistoryQuery(q string, now time.Time) (HistoryFilter, error) {
+	var f HistoryFilter
+	var words []string
+
+	for _, tok := range strings.Fields(q) {
+		key, val, ok := strings.Cut(tok, ":")
+		if !ok || val == "" {
+			words = append(words, tok)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "profile":
+			f.Profile = val
+		case "cell":
+			words = append(words, val)
+		case "status":
+			switch strings.ToLower(val) {
+			case "ok", "success", "0":
+				f.Status = "ok"
+			case "fail", "failed", "error":
+				f.Status = "fail"
+			default:
+				return f, fmt.Errorf("status must be ok or fail, got %q", val)
+			}
+		case "date":
+			since, until, err := parseHistoryDate(val, now)
+			if err != nil {
+				return f, err
+			}
+			f.Since, f.Until = since, until
+		default:
+			words = append(words, tok)
+		}
+	}
+	f.Cell = strings.Join(words, " ")
+	return f, nil
+}
+
+func parseHistoryDate(val string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(val) {
+	case "today":
+		return today, time.Time{}, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	}
+	if strings.HasSuffix(val, "d") {
+		var days int
+		if _, err := fmt.Sscanf(val, "%dd", &days); err == nil && days > 0 {
+			return now.AddDate(0, 0, -days), time.Time{}, nil
+		}
+	}
+	day, err := time.ParseInLocation("2006-01-02", val, now.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("date must be today, yesterday, Nd or YYYY-MM-DD, got %q", val)
+	}
+	return day, day.AddDate(0, 0, 1), nil
+}
+
+// historyMu serializes writers: foreground runs and background jobs both append.
+var historyMu sync.Mutex
+
+// HistoryPath returns the location of the history store.
+func HistoryPath() (string, error) {
+	cfgDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfgDir, historyFilename), nil
+}
+
+// AppendHistory records a completed execution and applies retention.
+func AppendHistory(e HistoryEntry) error {
+	path, err := HistoryPath()
+	if err != nil {
+		return err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, werr := f.Write(append(line, '\n'))
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return werr
+	}
+
+	return pruneHistory(path, historyMaxBytes, historyMaxAge, time.Now())
+}
+
+// recordHistory is AppendHistory for the execution paths, where a failure to log must not
+// affect the command itself.
+func recordHistory(e HistoryEntry) {
+	if err := AppendHistory(e); err != nil {
+		log.Printf("history error: %v", err)
+	}
+}
+
+// ReadHistory returns the entries matching filter, newest first.
+// Lines that fail to parse are skipped.
+func ReadHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	path, err := HistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	historyMu.Lock()
+	entries, err := loadHistory(path)
+	historyMu.Unlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var out []HistoryEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !filter.Match(entries[i]) {
+			continue
+		}
+		e := entries[i]
+		e.Argv = slices.Clone(e.Argv)
+		out = append(out, e)
+		if filter.Limit > 0 && len(out) >= filter.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// LastRuns returns the newest entry of every cell run in profile, keyed by cell name.
+func LastRuns(profile string) (map[string]HistoryEntry, error) {
+	entries, err := ReadHistory(HistoryFilter{Profile: profile})
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[string]HistoryEntry)
+	for _, e := range entries {
+		if _, seen := last[e.Cell]; !seen {
+			last[e.Cell] = e
+		}
+	}
+	return last, nil
+}
+
+// historyCache holds the parsed store for as long as the file is unchanged, so the grid can
+// look up the last runs every second without parsing it again.
+var historyCache struct {
+	path    string
+	size    int64
+	modTime time.Time
+	entries []HistoryEntry
+}
+
+// loadHistory is readHistoryFile through historyCache. The caller holds historyMu and must not
+// modify the entries.
+func loadHistory(path string) ([]HistoryEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &historyCache
+	if c.entries != nil && c.path == path && c.size == info.Size() && c.modTime.Equal(info.ModTime()) {
+		return c.entries, nil
+	}
+	entries, err := readHistoryFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c.path, c.size, c.modTime, c.entries = path, info.Size(), info.ModTime(), entries
+	return entries, nil
+}
+
+// readHistoryFile parses the store in file order (oldest first).
+func readHistoryFile(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// pruneHistory rewrites the store when it is over maxBytes or its oldest entry is older
+// than maxAge. Expired entries are dropped, then the oldest ones until the file is at
+// most half of maxBytes, so pruning does not run on every append.
+func pruneHistory(path string, maxBytes int64, maxAge time.Duration, now time.Time) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	cutoff := now.Add(-maxAge)
+	if info.Size() <= maxBytes {
+		oldest, ok := firstHistoryEntry(path)
+		if !ok || !oldest.Start.Before(cutoff) {
+			return nil
+		}
+	}
+
+	entries, err := readHistoryFile(path)
+	if err != nil {
+		return err
+	}
+
+	var lines [][]byte
+	var total int64
+	budget := maxBytes / 2
+	// Walk newest to oldest so the most recent entries win the size budget.
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Start.Before(cutoff) {
+			continue
+		}
+		line, err := json.Marshal(entries[i])
+		if err != nil {
+			continue
+		}
+		if total+int64(len(line))+1 > budget {
+			break
+		}
+		total += int64(len(line)) + 1
+		lines = append(lines, line)
+	}
+
+	var buf bytes.Buffer
+	for i := len(lines) - 1; i >= 0; i-- {
+		buf.Write(lines[i])
+		buf.WriteByte('\n')
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("could not write pruned history: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func firstHistoryEntry(path string) (HistoryEntry, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return HistoryEntry{}, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			return e, true
+		}
+	}
+	return HistoryEntry{}, false
+}
@@ -0,0 +1,267 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestAppendAndReadHistory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	base := time.Now().Add(-time.Hour)
+	entries := []HistoryEntry{
+		{Profile: "core", Cell: "Build", ExitCode: 0, Start: base, End: base.Add(time.Second)},
+		{Profile: "git", Cell: "Pull", ExitCode: 1, Error: "exit status 1", Start: base.Add(time.Minute)},
+		{Profile: "core", Cell: "Deploy", ExitCode: 0, Start: base.Add(2 * time.Minute)},
+	}
+	for _, e := range entries {
+		if err := AppendHistory(e); err != nil {
+			t.Fatalf("AppendHistory failed: %v", err)
+		}
+	}
+
+	all, err := ReadHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatalf("ReadHistory failed: %v", err)
+	}
+	if len(all) != 3 || all[0].Cell != "Deploy" || all[2].Cell != "Build" {
+		t.Fatalf("expected newest first, got %+v", all)
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{"profile", HistoryFilter{Profile: "CORE"}, []string{"Deploy", "Build"}},
+		{"cell substring", HistoryFilter{Cell: "pul"}, []string{"Pull"}},
+		{"failed only", HistoryFilter{Status: "fail"}, []string{"Pull"}},
+		{"ok only", HistoryFilter{Status: "ok"}, []string{"Deploy", "Build"}},
+		{"since", HistoryFilter{Since: base.Add(30 * time.Second)}, []string{"Deploy", "Pull"}},
+		{"limit", HistoryFilter{Limit: 1}, []string{"Deploy"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ReadHistory(tc.filter)
+			if err != nil {
+				t.Fatalf("ReadHistory failed: %v", err)
+			}
+			var names []string
+			for _, e := range got {
+				names = append(names, e.Cell)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tc.want) {
+				t.Fatalf("got %v, want %v", names, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadHistory_CachesUntilChanged(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := AppendHistory(HistoryEntry{Profile: "core", Cell: "Build", Start: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := HistoryPath()
+	if got, _ := ReadHistory(HistoryFilter{}); len(got) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", got)
+	}
+
+	// Same size and mtime: the parsed entries are reused
+	info, _ := os.Stat(path)
+	if err := os.WriteFile(path, bytes.Repeat([]byte(" "), int(info.Size())), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ReadHistory(HistoryFilter{}); len(got) != 1 || got[0].Cell != "Build" {
+		t.Fatalf("expected the cached entry, got %+v", got)
+	}
+
+	// An append changes the file, so it is read again
+	if err := AppendHistory(HistoryEntry{Profile: "core", Cell: "Deploy", Start: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ReadHistory(HistoryFilter{}); len(got) != 1 || got[0].Cell != "Deploy" {
+		t.Fatalf("expected the re-read store, got %+v", got)
+	}
+}
+
+func TestLastRuns(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	base := time.Now().Add(-time.Hour)
+	for _, e := range []HistoryEntry{
+		{Profile: "core", Cell: "Deploy", ExitCode: 0, Start: base},
+		{Profile: "core", Cell: "Deploy", ExitCode: 2, Error: "exit status 2", Start: base.Add(time.Minute)},
+		{Profile: "core", Cell: "Build", ExitCode: 0, Start: base.Add(2 * time.Minute)},
+		{Profile: "git", Cell: "Pull", ExitCode: 0, Start: base.Add(3 * time.Minute)},
+	} {
+		if err := AppendHistory(e); err != nil {
+			t.Fatalf("AppendHistory failed: %v", err)
+		}
+	}
+
+	last, err := LastRuns("core")
+	if err != nil {
+		t.Fatalf("LastRuns failed: %v", err)
+	}
+	if len(last) != 2 {
+		t.Fatalf("expected Deploy and Build only, got %+v", last)
+	}
+	if e := last["Deploy"]; e.Succeeded() || e.ExitCode != 2 {
+		t.Fatalf("expected the newest (failed) Deploy run, got %+v", e)
+	}
+	if !last["Build"].Succeeded() {
+		t.Fatalf("expected Build to have succeeded, got %+v", last["Build"])
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, historyFilename)
+	now := time.Now()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One expired entry followed by enough recent ones to exceed the size cap.
+	fmt.Fprintf(f, "{\"cell\":\"old\",\"start\":%q}\n", now.Add(-48*time.Hour).Format(time.RFC3339))
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(f, "{\"cell\":\"c%d\",\"start\":%q}\n", i, now.Format(time.RFC3339))
+	}
+	f.Close()
+
+	if err := pruneHistory(path, 2048, 24*time.Hour, now); err != nil {
+		t.Fatalf("pruneHistory failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 1024 {
+		t.Fatalf("expected pruned file to fit half the cap, got %d bytes", info.Size())
+	}
+	entries, err := readHistoryFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || entries[len(entries)-1].Cell != "c99" {
+		t.Fatalf("expected newest entries to be kept, got %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Cell == "old" {
+			t.Fatal("expired entry survived pruning")
+		}
+	}
+}
+
+func TestRunCommand_RecordsHistory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) {}
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands:     []config.Command{{Name: "fail", Command: "exit 4"}},
+	}
+	RunCommandWith(cfg, "fail", RunOptions{Profile: "core"})
+
+	got, err := ReadHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatalf("ReadHistory failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one entry, got %d", len(got))
+	}
+	e := got[0]
+	if e.Cell != "fail" || e.Profile != "core" || e.Shell != "sh" || e.Mode != ModeLive || e.ExitCode != 4 {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if e.End.Before(e.Start) || e.Cwd == "" {
+		t.Fatalf("missing timing or cwd: %+v", e)
+	}
+}
+
+func TestParseHistoryQuery(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.Local)
+	yesterday := time.Date(2024, 5, 9, 0, 0, 0, 0, time.Local)
+
+	f, err := ParseHistoryQuery("profile:git status:fail date:yesterday deploy prod", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Profile != "git" || f.Status != "fail" || f.Cell != "deploy prod" {
+		t.Fatalf("unexpected filter: %+v", f)
+	}
+	if !f.Since.Equal(yesterday) || !f.Until.Equal(yesterday.AddDate(0, 0, 1)) {
+		t.Fatalf("unexpected date window: %v - %v", f.Since, f.Until)
+	}
+
+	f, err = ParseHistoryQuery("date:2024-05-01 cell:build", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Cell != "build" || f.Since.Day() != 1 || f.Until.Day() != 2 {
+		t.Fatalf("unexpected filter: %+v", f)
+	}
+
+	f, err = ParseHistoryQuery("date:7d", now)
+	if err != nil || !f.Since.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("unexpected relative window: %+v (%v)", f, err)
+	}
+
+	for _, bad := range []string{"status:maybe", "date:lastweek"} {
+		if _, err := ParseHistoryQuery(bad, now); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestRunCommand_ReplayUsesRecordedArgvAndCwd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) {}
+
+	dir := t.TempDir()
+	replay := &HistoryEntry{
+		Profile: "git",
+		Cell:    "Touch",
+		Argv:    []string{"sh", "-c", "touch replayed"},
+		Cwd:     dir,
+		Shell:   "sh",
+	}
+	RunCommandWith(config.Config{}, "Touch", RunOptions{Profile: "core", Replay: replay})
+
+	if _, err := os.Stat(filepath.Join(dir, "replayed")); err != nil {
+		t.Fatalf("expected replay to run in original cwd: %v", err)
+	}
+	got, err := ReadHistory(HistoryFilter{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected one history entry, got %v (%v)", got, err)
+	}
+	if got[0].Profile != "git" || got[0].Cwd != dir || got[0].CommandLine() != "touch replayed" {
+		t.Fatalf("unexpected replay entry: %+v", got[0])
+	}
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+const (
+	defaultItemsTimeout = 10 * time.Second
+	defaultItemsCache   = 30 * time.Second
+	// maxGeneratedItems caps how many items a dropdown takes from items_command.
+	maxGeneratedItems = 100
+	// ItemPlaceholder is replaced by the item name in item_command.
+	ItemPlaceholder = config.ItemPlaceholder
+)
+
+// generatedItem is one item as printed by items_command in JSON form.
+type generatedItem struct {
+	Name        string `json:"name"`
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+type itemsCacheEntry struct {
+	items []config.CommandItem
+	at    time.Time
+}
+
+var (
+	itemsCacheMu sync.Mutex
+	itemsCache   = map[string]itemsCacheEntry{}
+)
+
+func itemsCacheKey(profile string, c config.Command) string {
+	return strings.Join([]string{profile, c.Name, c.ItemsCommand, c.ItemCommand}, "\x00")
+}
+
+// itemsDurations returns the timeout and cache lifetime of a cell's items_command.
+func itemsDurations(c config.Command) (timeout, ttl time.Duration, err error) {
+	timeout, ttl = defaultItemsTimeout, defaultItemsCache
+	if raw := strings.TrimSpace(c.ItemsTimeout); raw != "" {
+		if timeout, err = time.ParseDuration(raw); err != nil || timeout <= 0 {
+			return 0, 0, fmt.Errorf("invalid items_timeout %q", raw)
+		}
+	}
+	if raw := strings.TrimSpace(c.ItemsCache); raw != "" {
+		if raw == "0" {
+			return timeout, 0, nil
+		}
+		if ttl, err = time.ParseDuration(raw); err != nil || ttl < 0 {
+			return 0, 0, fmt.Errorf("invalid items_cache %q", raw)
+		}
+	}
+	return timeout, ttl, nil
+}
+
+// CachedItems returns the items generated for c in profile, if a fresh result is cached.
+func CachedItems(profile string, c config.Command) ([]config.CommandItem, bool) {
+	_, ttl, err := itemsDurations(c)
+	if err != nil || ttl == 0 {
+		return nil, false
+	}
+	itemsCacheMu.Lock()
+	defer itemsCacheMu.Unlock()
+	e, ok := itemsCache[itemsCacheKey(profile, c)]
+	if !ok || time.Since(e.at) > ttl {
+		return nil, false
+	}
+	return e.items, true
+}
+
+// GenerateItems runs the items_command of c where the cell runs (locally, on the profile's host
+// or in its container), with the cell's cwd and environment, and turns its output into dropdown
+// items. Successful results are cached for items_cache.
+func GenerateItems(cfg config.Config, c config.Command, opts RunOptions) ([]config.CommandItem, error) {
+	timeout, ttl, err := itemsDurations(c)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := ResolveCommandSpec(&c, nil)
+	commandStr, err := ExpandParams(c.ItemsCommand, cfg.DefaultShell, BuiltinVars(opts))
+	if err != nil {
+		return nil, err
+	}
+	run, err := runCaptured(cfg, spec, opts, commandStr, timeout)
+	if err == nil && run.exitCode != 0 {
+		err = fmt.Errorf("exit status %d", run.exitCode)
+	}
+	if err != nil {
+		if msg := lastLine(run.stderr); msg != "" {
+			return nil, fmt.Errorf("items_command: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("items_command: %w", err)
+	}
+
+	items, err := ParseItems(run.stdout, c.ItemCommand, cfg.DefaultShell)
+	if err != nil {
+		return nil, err
+	}
+	items = dropReservedItems(cfg, c.Name, items)
+	if ttl > 0 {
+		itemsCacheMu.Lock()
+		itemsCache[itemsCacheKey(opts.Profile, c)] = itemsCacheEntry{items: items, at: time.Now()}
+		itemsCacheMu.Unlock()
+	}
+	return items, nil
+}
+
+// ParseItems turns items_command output into dropdown items. The output is either a JSON array,
+// or one item per line: a JSON object or a plain name. Items without their own command get
+// itemCommand with {{item}} replaced by the name, quoted for shell.
+func ParseItems(output, itemCommand, shell string) ([]config.CommandItem, error) {
+	var raw []generatedItem
+	if trimmed := strings.TrimSpace(output); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &raw); err != nil {
+			return nil, fmt.Errorf("items_command output: %w", err)
+		}
+	} else {
+		for i, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			var g generatedItem
+			if strings.HasPrefix(line, "{") {
+				if err := json.Unmarshal([]byte(line), &g); err != nil {
+					return nil, fmt.Errorf("items_command output line %d: %w", i+1, err)
+				}
+			} else {
+				g.Name = line
+			}
+			raw = append(raw, g)
+		}
+	}
+
+	var items []config.CommandItem
+	seen := make(map[string]bool)
+	for _, g := range raw {
+		name := strings.TrimSpace(g.Name)
+		if name == "" {
+			return nil, fmt.Errorf("items_command printed an item without a name")
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		command := g.Command
+		if command == "" {
+			if strings.TrimSpace(itemCommand) == "" {
+				return nil, fmt.Errorf("item %q has no command; set item_command or print JSON with a command", name)
+			}
+			var err error
+			if command, err = ExpandParams(itemCommand, shell, map[string]string{ItemPlaceholder: name}); err != nil {
+				return nil, fmt.Errorf("item %q: %w", name, err)
+			}
+		}
+		items = append(items, config.CommandItem{Name: name, Command: command, Description: g.Description})
+		if len(items) == maxGeneratedItems {
+			break
+		}
+	}
+	return items, nil
+}
+
+// dropReservedItems leaves out generated items named like a configured cell or item, which
+// history and the jobs panel could mistake for it, or like one of drako's internal commands.
+func dropReservedItems(cfg config.Config, cell string, items []config.CommandItem) []config.CommandItem {
+	out := items[:0]
+	for _, item := range items {
+		if _, _, found := FindCommandByName(cfg, item.Name); found || strings.HasPrefix(item.Name, "drako ") {
+			log.Printf("items_command of %s: skipping item %q, the name is taken", cell, item.Name)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// lastLine returns the last non-empty line of s.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
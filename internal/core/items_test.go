@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestParseItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		template string
+		want     []config.CommandItem
+		wantErr  string
+	}{
+		{
+			name:     "lines",
+			output:   "web\n\n db \nweb\n",
+			template: "docker logs -f {{item}}",
+			want: []config.CommandItem{
+				{Name: "web", Command: "docker logs -f 'web'"},
+				{Name: "db", Command: "docker logs -f 'db'"},
+			},
+		},
+		{
+			name:     "json lines",
+			output:   `{"name": "up", "command": "make up", "description": "Start"}` + "\n" + "plain",
+			template: "make {{item}}",
+			want: []config.CommandItem{
+				{Name: "up", Command: "make up", Description: "Start"},
+				{Name: "plain", Command: "make 'plain'"},
+			},
+		},
+		{
+			name:   "json array",
+			output: `[{"name": "a", "command": "echo a"}]`,
+			want:   []config.CommandItem{{Name: "a", Command: "echo a"}},
+		},
+		{name: "no command", output: "web\n", wantErr: "set item_command"},
+		{name: "bad json", output: "{nope\n", template: "x", wantErr: "line 1"},
+		{name: "no name", output: `{"command": "x"}`, wantErr: "without a name"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseItems(tc.output, tc.template, "sh")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseItems failed: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+			for i := range got {
+				if got[i].Name != tc.want[i].Name || got[i].Command != tc.want[i].Command || got[i].Description != tc.want[i].Description {
+					t.Fatalf("item %d: got %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateItems_TimeoutAndCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := config.Config{DefaultShell: "sh"}
+
+	slow := config.Command{Name: "Slow", ItemsCommand: "sleep 5", ItemsTimeout: "100ms", ItemCommand: "x"}
+	if _, err := GenerateItems(cfg, slow, RunOptions{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	dir := t.TempDir()
+	cell := config.Command{Name: "Files", Cwd: dir, ItemsCommand: "pwd", ItemCommand: "ls {{item}}"}
+	items, err := GenerateItems(cfg, cell, RunOptions{Profile: "core"})
+	if err != nil || len(items) != 1 || !strings.HasSuffix(items[0].Name, dir) {
+		t.Fatalf("expected the item to come from the cell's cwd, got %+v, %v", items, err)
+	}
+	if cached, ok := CachedItems("core", cell); !ok || len(cached) != 1 {
+		t.Fatal("expected the items to be cached")
+	}
+	if _, ok := CachedItems("other", cell); ok {
+		t.Fatal("expected the cache to be per profile")
+	}
+	cell.ItemsCache = "0"
+	if _, ok := CachedItems("core", cell); ok {
+		t.Fatal("expected items_cache = \"0\" to disable the cache")
+	}
+}
+
+func TestGenerateItems_SkipsReservedNames(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cell := config.Command{Name: "Branches", ItemsCommand: `printf 'main\nDeploy\ndrako purge --config\nfix\n'`, ItemCommand: "git checkout {{item}}", ItemsCache: "0"}
+	cfg := config.Config{DefaultShell: "sh", Commands: []config.Command{
+		{Name: "Deploy", Command: "make deploy"},
+		cell,
+	}}
+	items, err := GenerateItems(cfg, cell, RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	if fmt.Sprint(names) != "[main fix]" {
+		t.Errorf("expected the taken names to be skipped, got %q", names)
+	}
+}
+
+func TestFindCommandIn_GeneratedOnlyThroughParent(t *testing.T) {
+	cfg := config.Config{Commands: []config.Command{
+		{Name: "A", Items: []config.CommandItem{{Name: "status", Command: "a"}}},
+		{Name: "B", Items: []config.CommandItem{{Name: "status", Command: "b"}}, Generated: []config.CommandItem{{Name: "feature", Command: "git checkout feature"}}},
+	}}
+	if _, _, found := FindCommandByName(cfg, "feature"); found {
+		t.Error("expected generated items to stay out of FindCommandByName")
+	}
+	if _, item, found := FindCommandIn(cfg, "B", "feature"); !found || item.Command != "git checkout feature" {
+		t.Errorf("expected the generated item through its parent, got %+v", item)
+	}
+	if _, item, found := FindCommandIn(cfg, "B", "status"); !found || item.Command != "b" {
+		t.Errorf("expected B's status, got %+v", item)
+	}
+	if _, _, found := FindCommandIn(cfg, "A", "feature"); found {
+		t.Error("expected another cell's items to be out of reach")
+	}
+}
@@ -0,0 +1,359 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// JobStatus describes where a background job is in its lifecycle.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "done"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+const (
+	// jobCancelGrace is how long a cancelled job gets to exit after SIGTERM before it is killed.
+	jobCancelGrace = 3 * time.Second
+	// maxJobLogs is the number of job logs kept in the jobs directory.
+	maxJobLogs = 50
+)
+
+// Job is a snapshot of a command started in the background.
+// Output (stdout and stderr) is written to LogPath.
+type Job struct {
+	ID       int
+	Name     string
+	Argv     []string
+	LogPath  string
+	Started  time.Time
+	Finished time.Time // Zero while running
+	ExitCode int       // -1 while running or when the process could not report one
+	Status   JobStatus
+	Err      string
+}
+
+// Duration returns how long the job ran, or has been running so far.
+func (j Job) Duration() time.Duration {
+	if j.Finished.IsZero() {
+		return time.Since(j.Started)
+	}
+	return j.Finished.Sub(j.Started)
+}
+
+// job is the manager's live record; Job is what callers get to see.
+type job struct {
+	Job
+	opts      RunOptions
+	notify    func(exitCode int, runErr error, d time.Duration)
+	cmd       *exec.Cmd
+	history   HistoryEntry
+	cancelled bool
+	done      chan struct{}
+}
+
+// jobManager tracks background jobs for the lifetime of the drako process.
+// It lives at package level so jobs outlive the TUI program being restarted between runs.
+type jobManager struct {
+	mu     sync.Mutex
+	nextID int
+	jobs   []*job
+}
+
+var jobs = &jobManager{}
+
+// jobsDir returns the directory holding per-job logs.
+func jobsDir() (string, error) {
+	cfgDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfgDir, "jobs"), nil
+}
+
+// StartJob resolves the selected command like RunCommandWith does, but starts it detached
+// with its output captured to a log file, and returns immediately.
+func StartJob(cfg config.Config, selected string, opts RunOptions) (Job, error) {
+	if opts.Parent == "" && strings.HasPrefix(selected, "drako ") {
+		return Job{}, fmt.Errorf("%s cannot run in the background", selected)
+	}
+
+	opts.detached = true
+	cmd, spec, err := prepareCommand(cfg, selected, opts)
+	if err != nil {
+		return Job{}, err
+	}
+	timeout, err := CommandTimeout(cfg, spec)
+	if err != nil {
+		return Job{}, err
+	}
+
+	dir, err := jobsDir()
+	if err != nil {
+		return Job{}, fmt.Errorf("could not locate jobs directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Job{}, fmt.Errorf("could not create jobs directory: %w", err)
+	}
+	pruneJobLogs(dir, maxJobLogs-1)
+
+	jobs.mu.Lock()
+	jobs.nextID++
+	id := jobs.nextID
+	jobs.mu.Unlock()
+
+	now := time.Now()
+	logPath := filepath.Join(dir, fmt.Sprintf("%s-%d.log", now.Format("20060102-150405"), id))
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return Job{}, fmt.Errorf("could not create job log: %w", err)
+	}
+	fmt.Fprintf(f, "# drako job #%d: %s\n# exec: %s\n# started: %s\n\n", id, spec.Name, strings.Join(cmd.Args, " "), now.Format("2006-01-02 15:04:05"))
+
+	cmd.Stdin = nil
+	cmd.Stdout = f
+	cmd.Stderr = f
+	cmd.SysProcAttr = detachedSysProcAttr()
+
+	entry := newHistoryEntry(cfg, opts, spec, cmd, ModeBackground)
+	if err := cmd.Start(); err != nil {
+		f.Close()
+		return Job{}, fmt.Errorf("could not start %s: %w", selected, err)
+	}
+
+	j := &job{
+		Job: Job{
+			ID:       id,
+			Name:     spec.Name,
+			Argv:     cmd.Args,
+			LogPath:  logPath,
+			Started:  now,
+			ExitCode: -1,
+			Status:   JobRunning,
+		},
+		opts: opts,
+		notify: func(exitCode int, runErr error, d time.Duration) {
+			notifyBackground(cfg, spec, exitCode, runErr, d)
+		},
+		cmd:     cmd,
+		history: entry,
+		done:    make(chan struct{}),
+	}
+
+	jobs.mu.Lock()
+	jobs.jobs = append(jobs.jobs, j)
+	jobs.mu.Unlock()
+
+	log.Printf("Started background job #%d: %s (exec: %s)", id, spec.Name, strings.Join(cmd.Args, " "))
+	go j.wait(f, timeout)
+
+	return j.Job, nil
+}
+
+// wait reaps the process, stopping it once timeout (if any) has passed, and records its outcome.
+func (j *job) wait(f *os.File, timeout time.Duration) {
+	done := make(chan error, 1)
+	go func() { done <- j.cmd.Wait() }()
+	var err error
+	if timeout > 0 {
+		// Jobs already run in their own process group (detachedSysProcAttr).
+		select {
+		case err = <-done:
+		case <-time.After(timeout):
+			err = stopTimedOut(j.cmd.Process, done, timeout)
+		}
+	} else {
+		err = <-done
+	}
+	end := time.Now()
+
+	jobs.mu.Lock()
+	j.Finished = end
+	if j.cmd.ProcessState != nil {
+		j.ExitCode = j.cmd.ProcessState.ExitCode()
+	}
+	switch {
+	case j.cancelled:
+		j.Status = JobCancelled
+	case err != nil:
+		j.Status = JobFailed
+		j.Err = err.Error()
+	default:
+		j.Status = JobSucceeded
+	}
+	status, code, cancelled := j.Status, j.ExitCode, j.cancelled
+	jobs.mu.Unlock()
+
+	recordHistory(finishHistoryEntry(j.history, j.cmd, err))
+	if !cancelled {
+		j.notify(code, err, end.Sub(j.Started))
+	}
+
+	fmt.Fprintf(f, "\n# finished: %s (%s, exit %d)\n", end.Format("2006-01-02 15:04:05"), status, code)
+	f.Close()
+	close(j.done)
+	log.Printf("Background job #%d finished: %s (exit %d)", j.ID, status, code)
+}
+
+// Jobs returns a snapshot of all jobs started in this session, newest first.
+func Jobs() []Job {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+
+	out := make([]Job, 0, len(jobs.jobs))
+	for i := len(jobs.jobs) - 1; i >= 0; i-- {
+		out = append(out, jobs.jobs[i].Job)
+	}
+	return out
+}
+
+// RunningJobs returns the number of jobs that have not finished yet.
+func RunningJobs() int {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+
+	n := 0
+	for _, j := range jobs.jobs {
+		if j.Status == JobRunning {
+			n++
+		}
+	}
+	return n
+}
+
+func findJob(id int) (*job, error) {
+	for _, j := range jobs.jobs {
+		if j.ID == id {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("job #%d not found", id)
+}
+
+// CancelJob stops a running job: its process group gets SIGTERM and, if it is
+// still alive after a grace period, SIGKILL.
+func CancelJob(id int) error {
+	jobs.mu.Lock()
+	j, err := findJob(id)
+	if err != nil {
+		jobs.mu.Unlock()
+		return err
+	}
+	if j.Status != JobRunning {
+		jobs.mu.Unlock()
+		return fmt.Errorf("job #%d is not running", id)
+	}
+	j.cancelled = true
+	proc := j.cmd.Process
+	jobs.mu.Unlock()
+
+	if err := terminateProcessGroup(proc); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("could not stop job #%d: %w", id, err)
+	}
+
+	go func() {
+		select {
+		case <-j.done:
+		case <-time.After(jobCancelGrace):
+			_ = killProcessGroup(proc)
+		}
+	}()
+	return nil
+}
+
+// JobRunOptions returns the options a job was started with, so it can be run again.
+func JobRunOptions(id int) (RunOptions, error) {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	j, err := findJob(id)
+	if err != nil {
+		return RunOptions{}, err
+	}
+	return j.opts, nil
+}
+
+// TailJobLog returns the last n lines of a job's log.
+func TailJobLog(id int, n int) ([]string, error) {
+	jobs.mu.Lock()
+	j, err := findJob(id)
+	if err != nil {
+		jobs.mu.Unlock()
+		return nil, err
+	}
+	path := j.LogPath
+	jobs.mu.Unlock()
+	return tailFile(path, n)
+}
+
+// tailFile returns the last n lines of a file (all lines if n <= 0).
+func tailFile(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if n > 0 && len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// pruneJobLogs removes the oldest job logs so that at most keep remain.
+// Logs of jobs that are still running in this session are never removed.
+func pruneJobLogs(dir string, keep int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var logs []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") {
+			logs = append(logs, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(logs) <= keep {
+		return
+	}
+	// Names start with a sortable timestamp, so lexical order is chronological.
+	sort.Strings(logs)
+
+	jobs.mu.Lock()
+	running := make(map[string]bool)
+	for _, j := range jobs.jobs {
+		if j.Status == JobRunning {
+			running[j.LogPath] = true
+		}
+	}
+	jobs.mu.Unlock()
+
+	for _, path := range logs[:len(logs)-keep] {
+		if running[path] {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("Failed to prune job log %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+//go:build !windows
+
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// waitForJob polls the job manager until the job leaves the running state.
+func waitForJob(t *testing.T, id int) Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, j := range Jobs() {
+			if j.ID == id && j.Status != JobRunning {
+				return j
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("job #%d did not finish in time", id)
+	return Job{}
+}
+
+func TestStartJob_CapturesOutputAndExitCode(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{
+			{Name: "ok", Command: "echo hello from job"},
+			{Name: "fail", Command: "echo broken >&2; exit 3"},
+		},
+	}
+
+	started, err := StartJob(cfg, "ok", RunOptions{})
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	j := waitForJob(t, started.ID)
+	if j.Status != JobSucceeded || j.ExitCode != 0 {
+		t.Fatalf("expected success with exit 0, got %s exit %d", j.Status, j.ExitCode)
+	}
+	data, err := os.ReadFile(j.LogPath)
+	if err != nil {
+		t.Fatalf("could not read job log: %v", err)
+	}
+	if !strings.Contains(string(data), "hello from job") {
+		t.Fatalf("job log missing output:\n%s", data)
+	}
+
+	started, err = StartJob(cfg, "fail", RunOptions{})
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	j = waitForJob(t, started.ID)
+	if j.Status != JobFailed || j.ExitCode != 3 {
+		t.Fatalf("expected failure with exit 3, got %s exit %d", j.Status, j.ExitCode)
+	}
+	tail, err := TailJobLog(j.ID, 5)
+	if err != nil {
+		t.Fatalf("TailJobLog failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(tail, "\n"), "broken") {
+		t.Fatalf("stderr not captured in tail: %v", tail)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands:     []config.Command{{Name: "slow", Command: "sleep 30"}},
+	}
+
+	started, err := StartJob(cfg, "slow", RunOptions{})
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	if err := CancelJob(started.ID); err != nil {
+		t.Fatalf("CancelJob failed: %v", err)
+	}
+	j := waitForJob(t, started.ID)
+	if j.Status != JobCancelled {
+		t.Fatalf("expected cancelled, got %s", j.Status)
+	}
+	if err := CancelJob(started.ID); err == nil {
+		t.Fatal("expected error cancelling a finished job")
+	}
+}
+
+func TestStartJob_RejectsInternalCommands(t *testing.T) {
+	if _, err := StartJob(config.Config{}, "drako purge --config", RunOptions{}); err == nil {
+		t.Fatal("expected internal commands to be rejected")
+	}
+}
+
+func TestStartJob_ContainerWithoutTerminal(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stubContainerRuntime(t, "true")
+	background := true
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Container:    &config.ContainerTarget{Name: "devbox"},
+		Commands:     []config.Command{{Name: "Build", Command: "make", Background: &background}},
+	}
+
+	job, err := StartJob(cfg, "Build", RunOptions{})
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	waitForJob(t, job.ID)
+	want := []string{"/usr/bin/podman", "exec", "devbox", "sh", "-c", "make"}
+	if strings.Join(job.Argv, "|") != strings.Join(want, "|") {
+		t.Errorf("args = %q, want %q", job.Argv, want)
+	}
+}
+
+func TestStartJob_QueuesNotification(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	TakeTerminalNotices()
+	notify := true
+	cfg := config.Config{
+		DefaultShell: "sh",
+		NotifyMethod: NotifyOSC9,
+		Commands:     []config.Command{{Name: "Build", Command: "exit 2", Notify: &notify}},
+	}
+
+	started, err := StartJob(cfg, "Build", RunOptions{})
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	waitForJob(t, started.ID)
+	deadline := time.Now().Add(2 * time.Second)
+	var notices []string
+	for len(notices) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		notices = TakeTerminalNotices()
+	}
+	if len(notices) != 1 || !strings.Contains(notices[0], "drako: Build: failed (exit 2)") {
+		t.Fatalf("expected the job's notification to be queued, got %q", notices)
+	}
+}
+
+func TestStartJob_Timeout(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands:     []config.Command{{Name: "hang", Command: "sleep 30", Timeout: "100ms"}},
+	}
+
+	started, err := StartJob(cfg, "hang", RunOptions{})
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	got := waitForJob(t, started.ID)
+	if got.Status != JobFailed || !strings.Contains(got.Err, "timed out after 100ms") {
+		t.Fatalf("expected the job to time out, got %s (%q)", got.Status, got.Err)
+	}
+	if got.Duration() > 5*time.Second {
+		t.Errorf("expected the job to be stopped soon after its timeout, took %s", got.Duration())
+	}
+
+	cfg.Commands[0].Timeout = "soon"
+	if _, err := StartJob(cfg, "hang", RunOptions{}); err == nil {
+		t.Error("expected an invalid timeout to be rejected")
+	}
+}
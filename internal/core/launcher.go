@@ -0,0 +1,244 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// LauncherInline runs commands in drako's own terminal (the TUI steps aside meanwhile).
+const LauncherInline = "inline"
+
+// LaunchRequest is a fully prepared command to be opened outside drako's terminal.
+type LaunchRequest struct {
+	Name string   // Cell name, used as window title where supported
+	Argv []string // Command to run, already wrapped so it gets exactly Env
+	Dir  string   // Working directory
+	Env  []string // Environment the command must see
+	Hold bool     // Keep the target open after the command exits (auto_close_execution = false)
+}
+
+// Launcher opens commands somewhere other than drako's own terminal, e.g. a tmux window,
+// so the deck stays on screen. New multiplexers implement it and call RegisterLauncher.
+type Launcher interface {
+	// Available reports whether the launcher can be used from the current session.
+	Available() bool
+	// Command returns the argv that opens req in a new target.
+	Command(req LaunchRequest) []string
+}
+
+var launchers = map[string]Launcher{}
+
+// RegisterLauncher makes a launcher selectable by name in the launcher setting.
+func RegisterLauncher(name string, l Launcher) {
+	launchers[name] = l
+}
+
+// LauncherNames lists the registered launchers, sorted.
+func LauncherNames() []string {
+	names := make([]string, 0, len(launchers))
+	for name := range launchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EffectiveLauncher returns the launcher a command uses: its own, else the profile
+// default, else inline.
+func EffectiveLauncher(cfg config.Config, spec CommandSpec) (string, error) {
+	name := strings.TrimSpace(spec.Launcher)
+	if name == "" {
+		name = strings.TrimSpace(cfg.Launcher)
+	}
+	if name == "" || name == LauncherInline {
+		return LauncherInline, nil
+	}
+	if _, ok := launchers[name]; !ok {
+		return "", fmt.Errorf("unknown launcher %q (use %s or one of %s)", name, LauncherInline, strings.Join(LauncherNames(), ", "))
+	}
+	return name, nil
+}
+
+// LauncherAvailable reports whether the named launcher can be used right now.
+// Inline is always available.
+func LauncherAvailable(name string) bool {
+	if name == LauncherInline {
+		return true
+	}
+	l, ok := launchers[name]
+	return ok && l.Available()
+}
+
+// Launch prepares the selected cell like RunCommand would and opens it with the named launcher.
+// It returns once the launcher has started. History records whether the launch succeeded; when
+// the cell may notify, the pane reports its exit code back for the completion notification.
+func Launch(cfg config.Config, selected, launcher string, opts RunOptions) error {
+	l, ok := launchers[launcher]
+	if !ok {
+		return fmt.Errorf("unknown launcher %q", launcher)
+	}
+	cmd, spec, err := prepareCommand(cfg, selected, opts)
+	if err != nil {
+		return err
+	}
+
+	dir := cmd.Dir
+	if dir == "" {
+		if dir, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	req := LaunchRequest{
+		Name: spec.Name,
+		Dir:  dir,
+		Env:  env,
+		Hold: !boolOrDefault(spec.AutoCloseExecution, true),
+	}
+	var statusFile string
+	if mayNotify(cfg, spec) {
+		if f, err := os.CreateTemp("", "drako-launch-*.status"); err != nil {
+			log.Printf("no completion notification for %s: %v", spec.Name, err)
+		} else {
+			statusFile = f.Name()
+			f.Close()
+		}
+	}
+	envFile, err := writeLaunchEnv(req.Env)
+	if err != nil {
+		if statusFile != "" {
+			os.Remove(statusFile)
+		}
+		return fmt.Errorf("could not pass the environment to %s: %w", launcher, err)
+	}
+	req.Argv = wrapForLaunch(cmd.Args, envFile, req.Hold, statusFile)
+
+	argv := l.Command(req)
+	launch := commandFn(argv[0], argv[1:]...)
+	entry := newHistoryEntry(cfg, opts, spec, cmd, launcher)
+	if err := launch.Start(); err != nil {
+		recordHistory(finishHistoryEntry(entry, launch, err))
+		os.Remove(envFile)
+		if statusFile != "" {
+			os.Remove(statusFile)
+		}
+		return fmt.Errorf("%s: %w", launcher, err)
+	}
+	if statusFile != "" {
+		go watchLaunch(cfg, spec, statusFile, time.Now())
+	}
+	// Some targets (tmux popups) keep the launcher process around until they close,
+	// so it is reaped in the background instead of blocking the TUI.
+	go func() {
+		err := launch.Wait()
+		recordHistory(finishHistoryEntry(entry, launch, err))
+	}()
+	return nil
+}
+
+// Pieces of the sh script that wraps a launched command.
+const (
+	// launchRun runs the script's arguments and keeps their exit status.
+	launchRun = `"$@"; status=$?`
+	// launchRecord writes the status to the file named by $0, for watchLaunch.
+	launchRecord = `; printf '%d\n' "$status" > "$0"`
+	// launchHold waits for Enter, so the output stays readable.
+	launchHold = `; printf '\n--- Command Finished (exit %d) ---\nPress Enter to close.' "$status"; read _`
+)
+
+// holdScript runs its arguments and waits for Enter.
+const holdScript = launchRun + launchHold
+
+// launchLoadEnv loads and removes the env file given as $1, then drops it from the arguments.
+const launchLoadEnv = `set -a; . "$1"; set +a; rm -f "$1"; shift; `
+
+// wrapForLaunch returns argv that runs args with exactly the variables of envFile (multiplexers
+// otherwise hand new panes their own server environment), optionally writes the exit status to
+// statusFile and optionally holds the pane open afterwards.
+func wrapForLaunch(args []string, envFile string, hold bool, statusFile string) []string {
+	script, name := `exec "$@"`, "drako"
+	if hold || statusFile != "" {
+		script = launchRun
+		if statusFile != "" {
+			script, name = script+launchRecord, statusFile
+		}
+		if hold {
+			script += launchHold
+		}
+	}
+	argv := []string{"env", "-i", "/bin/sh", "-c", launchLoadEnv + script, name, envFile}
+	return append(argv, args...)
+}
+
+// writeLaunchEnv writes env to a private file of sh assignments for wrapForLaunch, so the
+// values stay off the launcher's command line. Variables sh cannot assign are left out.
+func writeLaunchEnv(env []string) (string, error) {
+	var b strings.Builder
+	for _, kv := range env {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || !envNamePattern.MatchString(k) {
+			continue
+		}
+		q, err := QuoteForShell("sh", v)
+		if err != nil {
+			continue
+		}
+		b.WriteString(k + "=" + q + "\n")
+	}
+	f, err := os.CreateTemp("", "drako-launch-*.env")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// Launched panes are checked for their exit status this often, for at most a day.
+const (
+	launchWatchInterval = time.Second
+	launchWatchLimit    = 24 * time.Hour
+)
+
+// mayNotify reports whether a run of spec can end with a notification, before its duration is known.
+func mayNotify(cfg config.Config, spec CommandSpec) bool {
+	if spec.Notify != nil {
+		return *spec.Notify
+	}
+	return strings.TrimSpace(cfg.NotifyAfter) != ""
+}
+
+// watchLaunch waits for a launched pane to write its exit status to statusFile, then sends the
+// completion notification and removes the file.
+func watchLaunch(cfg config.Config, spec CommandSpec, statusFile string, started time.Time) {
+	defer os.Remove(statusFile)
+	for time.Since(started) < launchWatchLimit {
+		time.Sleep(launchWatchInterval)
+		data, err := os.ReadFile(statusFile)
+		if err != nil {
+			return
+		}
+		// The line is complete once its newline is there.
+		if line := string(data); strings.HasSuffix(line, "\n") {
+			code, err := strconv.Atoi(strings.TrimSpace(line))
+			if err != nil {
+				code = -1
+			}
+			notifyBackground(cfg, spec, code, nil, time.Since(started))
+			return
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestEffectiveLauncher(t *testing.T) {
+	profile := config.Config{Launcher: "tmux-split"}
+
+	if got, _ := EffectiveLauncher(config.Config{}, CommandSpec{}); got != LauncherInline {
+		t.Errorf("expected inline by default, got %q", got)
+	}
+	if got, _ := EffectiveLauncher(profile, CommandSpec{}); got != "tmux-split" {
+		t.Errorf("expected profile default, got %q", got)
+	}
+	if got, _ := EffectiveLauncher(profile, CommandSpec{Launcher: "inline"}); got != LauncherInline {
+		t.Errorf("expected command setting to win, got %q", got)
+	}
+	if _, err := EffectiveLauncher(config.Config{}, CommandSpec{Launcher: "screen"}); err == nil || !strings.Contains(err.Error(), "tmux-window") {
+		t.Errorf("expected unknown launcher error listing the options, got %v", err)
+	}
+}
+
+func TestTmuxLauncher_Command(t *testing.T) {
+	req := LaunchRequest{Name: "Logs", Dir: "/srv", Argv: []string{"env", "-i", "A=1", "tail", "-f", "log"}}
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"window", []string{"tmux", "new-window", "-n", "Logs", "-c", "/srv", "--", "env", "-i", "A=1", "tail", "-f", "log"}},
+		{"split", []string{"tmux", "split-window", "-c", "/srv", "--", "env", "-i", "A=1", "tail", "-f", "log"}},
+		{"popup", []string{"tmux", "display-popup", "-E", "-w", "80%", "-h", "80%", "-d", "/srv", "--", "env", "-i", "A=1", "tail", "-f", "log"}},
+	}
+	for _, tc := range tests {
+		if got := (tmuxLauncher{target: tc.target}).Command(req); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.target, got, tc.want)
+		}
+	}
+}
+
+func TestTmuxLauncher_AvailableOnlyInsideTmux(t *testing.T) {
+	oldLookPath := lookPathFn
+	defer func() { lookPathFn = oldLookPath }()
+	lookPathFn = func(string) (string, error) { return "/usr/bin/tmux", nil }
+
+	t.Setenv("TMUX", "")
+	if LauncherAvailable("tmux-window") {
+		t.Fatal("expected tmux launcher to be unavailable outside tmux")
+	}
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	if !LauncherAvailable("tmux-window") {
+		t.Fatal("expected tmux launcher to be available inside tmux")
+	}
+}
+
+func TestLaunch_WrapsEnvAndHold(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses true")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var launched []string
+	oldCommand := commandFn
+	defer func() { commandFn = oldCommand }()
+	commandFn = func(name string, args ...string) *exec.Cmd {
+		launched = append([]string{name}, args...)
+		return exec.Command("true")
+	}
+
+	dir := t.TempDir()
+	noClose := false
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{{
+			Name:               "Tail",
+			Command:            "tail -f app.log",
+			Cwd:                dir,
+			Env:                map[string]string{"LOG_LEVEL": "debug"},
+			AutoCloseExecution: &noClose,
+		}},
+	}
+	if err := Launch(cfg, "Tail", "tmux-window", RunOptions{Profile: "ops"}); err != nil {
+		t.Fatalf("Launch failed: %v", err)
+	}
+
+	// The launcher process is reaped in the background; wait for its history entry.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, _ := ReadHistory(HistoryFilter{})
+		if len(got) == 1 {
+			if got[0].Mode != "tmux-window" || !got[0].Succeeded() {
+				t.Fatalf("unexpected launch entry: %+v", got[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("launch was not recorded in history")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	line := strings.Join(launched, " ")
+	for _, want := range []string{"tmux new-window -n Tail -c " + dir + " -- env -i /bin/sh -c " + launchLoadEnv + holdScript + " drako "} {
+		if !strings.Contains(line, want) {
+			t.Errorf("launch argv missing %q:\n%s", want, line)
+		}
+	}
+	if strings.Contains(line, "LOG_LEVEL") || !strings.HasSuffix(line, " sh -c tail -f app.log") {
+		t.Errorf("expected env values off the launch argv:\n%s", line)
+	}
+
+	// The values are in a private file the pane loads (and removes)
+	envFile := launched[slices.Index(launched, "drako")+1]
+	defer os.Remove(envFile)
+	info, err := os.Stat(envFile)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a 0600 env file, got %v (%v)", info, err)
+	}
+	data, _ := os.ReadFile(envFile)
+	for _, want := range []string{"LOG_LEVEL='debug'\n", "DRAKO_PROFILE='ops'\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("env file missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestWrapForLaunch_LoadsEnvFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	envFile, err := writeLaunchEnv([]string{"A=it's $x", "BAD-NAME=1", "B=two\nlines"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	argv := wrapForLaunch([]string{"sh", "-c", `printf '%s|%s' "$A" "$B"`}, envFile, false, "")
+	out, err := exec.Command(argv[0], argv[1:]...).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "it's $x|two\nlines" {
+		t.Errorf("pane saw %q", out)
+	}
+	if _, err := os.Stat(envFile); !os.IsNotExist(err) {
+		t.Error("expected the env file to be removed once loaded")
+	}
+}
+
+func TestWatchLaunch_NotifiesWithExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("tmux launchers are unix-only")
+	}
+	t.Setenv("TMUX", "")
+	TakeTerminalNotices()
+	status := filepath.Join(t.TempDir(), "tail.status")
+	if err := os.WriteFile(status, []byte("3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notify := true
+	watchLaunch(config.Config{NotifyMethod: NotifyOSC9}, CommandSpec{Name: "Tail", Notify: &notify}, status, time.Now())
+
+	if notices := TakeTerminalNotices(); len(notices) != 1 || !strings.Contains(notices[0], "drako: Tail: failed (exit 3)") {
+		t.Fatalf("expected the pane's notification, got %q", notices)
+	}
+	if _, err := os.Stat(status); !os.IsNotExist(err) {
+		t.Error("expected the status file to be removed")
+	}
+
+	argv := wrapForLaunch([]string{"make"}, "a.env", false, status)
+	want := []string{"env", "-i", "/bin/sh", "-c", launchLoadEnv + launchRun + launchRecord, status, "a.env", "make"}
+	if strings.Join(argv, "|") != strings.Join(want, "|") {
+		t.Errorf("argv = %q, want %q", argv, want)
+	}
+}
@@ -0,0 +1,37 @@
+package core
+
+import "os"
+
+func init() {
+	RegisterLauncher("tmux-window", tmuxLauncher{target: "window"})
+	RegisterLauncher("tmux-split", tmuxLauncher{target: "split"})
+	RegisterLauncher("tmux-popup", tmuxLauncher{target: "popup"})
+}
+
+// tmuxLauncher opens commands in a new tmux window, a split of the current pane or a popup.
+type tmuxLauncher struct {
+	target string
+}
+
+// Available requires drako itself to run inside tmux.
+func (t tmuxLauncher) Available() bool {
+	if os.Getenv("TMUX") == "" {
+		return false
+	}
+	_, err := lookPathFn("tmux")
+	return err == nil
+}
+
+func (t tmuxLauncher) Command(req LaunchRequest) []string {
+	var argv []string
+	switch t.target {
+	case "split":
+		argv = []string{"tmux", "split-window", "-c", req.Dir}
+	case "popup":
+		// -E closes the popup when the command exits; holding is done by the wrapper.
+		argv = []string{"tmux", "display-popup", "-E", "-w", "80%", "-h", "80%", "-d", req.Dir}
+	default:
+		argv = []string{"tmux", "new-window", "-n", req.Name, "-c", req.Dir}
+	}
+	return append(append(argv, "--"), req.Argv...)
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// Notification methods for notify_method.
+const (
+	NotifyBell    = "bell"
+	NotifyOSC9    = "osc9"
+	NotifyOSC777  = "osc777"
+	NotifyCommand = "command"
+)
+
+// notifyOut is where bells and escape sequences are written; a test seam.
+var notifyOut io.Writer = os.Stdout
+
+// notifyCommandTimeout bounds how long a notifier command may take.
+const notifyCommandTimeout = 5 * time.Second
+
+// terminalNotices holds the bells and escape sequences of runs that finished in the background
+// (jobs, launcher panes). The TUI owns the terminal then, so it writes them out itself
+// (TakeTerminalNotices) instead of core writing in the middle of a frame.
+var (
+	terminalNoticesMu sync.Mutex
+	terminalNotices   []string
+)
+
+// maxTerminalNotices caps the queue while nothing takes from it; older notices are dropped.
+const maxTerminalNotices = 16
+
+// ShouldNotify reports whether a run of spec that took d ends with a notification:
+// the command's notify setting wins, otherwise runs of at least notify_after qualify.
+func ShouldNotify(cfg config.Config, spec CommandSpec, d time.Duration) bool {
+	if spec.Notify != nil {
+		return *spec.Notify
+	}
+	raw := strings.TrimSpace(cfg.NotifyAfter)
+	if raw == "" {
+		return false
+	}
+	threshold, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("invalid notify_after %q: %v", raw, err)
+		return false
+	}
+	return d >= threshold
+}
+
+// notifyMessage is the text of a completion notification, e.g. "failed (exit 2) after 3m12s".
+func notifyMessage(exitCode int, runErr error, d time.Duration) string {
+	status := "finished"
+	switch {
+	case errors.Is(runErr, errTimedOut):
+		status = "timed out"
+	case exitCode > 0:
+		status = fmt.Sprintf("failed (exit %d)", exitCode)
+	case runErr != nil:
+		status = "failed"
+	}
+	return fmt.Sprintf("%s after %s", status, d.Round(time.Second))
+}
+
+// notifyDone sends the completion notification for a run of spec, if one is due.
+func notifyDone(cfg config.Config, spec CommandSpec, exitCode int, runErr error, d time.Duration) {
+	if !ShouldNotify(cfg, spec, d) {
+		return
+	}
+	title := "drako: " + spec.Name
+	if err := Notify(cfg, title, notifyMessage(exitCode, runErr, d)); err != nil {
+		log.Printf("notification for %s failed: %v", spec.Name, err)
+	}
+}
+
+// notifyBackground is notifyDone for runs that finish while the TUI may be on screen: terminal
+// notifications are queued for TakeTerminalNotices, notifier commands run right away.
+func notifyBackground(cfg config.Config, spec CommandSpec, exitCode int, runErr error, d time.Duration) {
+	if !ShouldNotify(cfg, spec, d) {
+		return
+	}
+	title := "drako: " + spec.Name
+	message := notifyMessage(exitCode, runErr, d)
+	seq, err := terminalNotification(cfg, title, message)
+	switch {
+	case err != nil:
+	case seq == "":
+		err = Notify(cfg, title, message)
+	default:
+		terminalNoticesMu.Lock()
+		terminalNotices = append(terminalNotices, seq)
+		if len(terminalNotices) > maxTerminalNotices {
+			terminalNotices = terminalNotices[len(terminalNotices)-maxTerminalNotices:]
+		}
+		terminalNoticesMu.Unlock()
+	}
+	if err != nil {
+		log.Printf("notification for %s failed: %v", spec.Name, err)
+	}
+}
+
+// TakeTerminalNotices returns the queued terminal notifications of background runs and clears
+// the queue. Write them with WriteTerminalNotices.
+func TakeTerminalNotices() []string {
+	terminalNoticesMu.Lock()
+	defer terminalNoticesMu.Unlock()
+	notices := terminalNotices
+	terminalNotices = nil
+	return notices
+}
+
+// WriteTerminalNotices writes notifications taken with TakeTerminalNotices to the terminal.
+func WriteTerminalNotices(notices []string) error {
+	_, err := io.WriteString(notifyOut, strings.Join(notices, ""))
+	return err
+}
+
+// Notify delivers a notification with the configured method.
+func Notify(cfg config.Config, title, message string) error {
+	seq, err := terminalNotification(cfg, title, message)
+	if err != nil {
+		return err
+	}
+	if seq != "" {
+		_, err := io.WriteString(notifyOut, seq)
+		return err
+	}
+
+	argv := strings.Fields(cfg.NotifyCommand)
+	if len(argv) == 0 {
+		return fmt.Errorf("notify_method %q needs notify_command", NotifyCommand)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyCommandTimeout)
+	defer cancel()
+	cmd := commandFn(argv[0], append(argv[1:], title, message)...)
+	cmd.Env = CommandEnv(cfg)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("%s did not finish within %s", argv[0], notifyCommandTimeout)
+	}
+}
+
+// terminalNotification returns what the configured method writes to the terminal, or "" for
+// the command method.
+func terminalNotification(cfg config.Config, title, message string) (string, error) {
+	method := strings.TrimSpace(cfg.NotifyMethod)
+	switch method {
+	case "", NotifyBell:
+		return "\a", nil
+	case NotifyOSC9:
+		return terminalSequence(fmt.Sprintf("\x1b]9;%s: %s\x07", oscText(title), oscText(message))), nil
+	case NotifyOSC777:
+		// The title is a field of its own; a ";" in it would shift the message.
+		title = strings.ReplaceAll(oscText(title), ";", "")
+		return terminalSequence(fmt.Sprintf("\x1b]777;notify;%s;%s\x07", title, oscText(message))), nil
+	case NotifyCommand:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown notify_method %q (use bell, osc9, osc777 or command)", method)
+	}
+}
+
+// oscText makes s safe inside an OSC sequence: control characters (BEL, ESC and the C1 range,
+// which includes ST) would end the sequence early and let the rest reach the terminal as input.
+// Tabs and line breaks become spaces, the others are dropped.
+func oscText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return ' '
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// terminalSequence wraps an OSC sequence for tmux so it reaches the outer terminal.
+func terminalSequence(seq string) string {
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestShouldNotify(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		after  string
+		notify *bool
+		took   time.Duration
+		want   bool
+	}{
+		{"disabled by default", "", nil, time.Hour, false},
+		{"below threshold", "2m", nil, time.Minute, false},
+		{"at threshold", "2m", nil, 2 * time.Minute, true},
+		{"command forces on", "", &yes, time.Second, true},
+		{"command forces off", "1s", &no, time.Hour, false},
+		{"invalid threshold", "soon", nil, time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{NotifyAfter: tt.after}
+			if got := ShouldNotify(cfg, CommandSpec{Notify: tt.notify}, tt.took); got != tt.want {
+				t.Errorf("ShouldNotify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyMessage(t *testing.T) {
+	tests := []struct {
+		code int
+		err  error
+		want string
+	}{
+		{0, nil, "finished after 3m12s"},
+		{2, errors.New("exit status 2"), "failed (exit 2) after 3m12s"},
+		{-1, fmt.Errorf("%w after 1m", errTimedOut), "timed out after 3m12s"},
+	}
+	for _, tt := range tests {
+		if got := notifyMessage(tt.code, tt.err, 3*time.Minute+12*time.Second); got != tt.want {
+			t.Errorf("notifyMessage(%d, %v) = %q, want %q", tt.code, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNotify_Methods(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses true")
+	}
+	t.Setenv("TMUX", "")
+	oldOut, oldCmd := notifyOut, commandFn
+	defer func() { notifyOut, commandFn = oldOut, oldCmd }()
+	var out bytes.Buffer
+	notifyOut = &out
+
+	tests := []struct{ method, want string }{
+		{"", "\a"},
+		{NotifyOSC9, "\x1b]9;drako: Build: finished\x07"},
+		{NotifyOSC777, "\x1b]777;notify;drako: Build;finished\x07"},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		if err := Notify(config.Config{NotifyMethod: tt.method}, "drako: Build", "finished"); err != nil {
+			t.Fatalf("%q: %v", tt.method, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%q wrote %q, want %q", tt.method, out.String(), tt.want)
+		}
+	}
+
+	var gotArgs []string
+	commandFn = func(name string, args ...string) *exec.Cmd {
+		gotArgs = append([]string{name}, args...)
+		return exec.Command("true")
+	}
+	cfg := config.Config{NotifyMethod: NotifyCommand, NotifyCommand: "notify-send -u low"}
+	if err := Notify(cfg, "drako: Build", "finished"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"notify-send", "-u", "low", "drako: Build", "finished"}
+	if fmt.Sprint(gotArgs) != fmt.Sprint(want) {
+		t.Errorf("notifier args = %q, want %q", gotArgs, want)
+	}
+
+	// Control characters from cell names cannot end the sequence or inject their own
+	for method, want := range map[string]string{
+		NotifyOSC9:   "\x1b]9;drako: Build]0;pwned: finished x\x07",
+		NotifyOSC777: "\x1b]777;notify;drako: Build]0pwned;finished x\x07",
+	} {
+		out.Reset()
+		if err := Notify(config.Config{NotifyMethod: method}, "drako: Build\a\x1b]0;pwned\u009c", "finished\nx\x1b"); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != want {
+			t.Errorf("%q wrote %q, want %q", method, out.String(), want)
+		}
+	}
+
+	if err := Notify(config.Config{NotifyMethod: "pigeon"}, "t", "m"); err == nil {
+		t.Error("expected unknown method to be rejected")
+	}
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// Parameter types a command can declare.
+const (
+	ParamString = "string"
+	ParamChoice = "choice"
+	ParamPath   = "path"
+	ParamNumber = "number"
+)
+
+// placeholderPattern matches {{name}} references inside a command string.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ParamType returns the normalized type of a parameter. Unknown or empty types are treated as strings.
+func ParamType(p config.CommandParam) string {
+	switch strings.ToLower(strings.TrimSpace(p.Type)) {
+	case ParamChoice:
+		return ParamChoice
+	case ParamPath:
+		return ParamPath
+	case ParamNumber:
+		return ParamNumber
+	default:
+		return ParamString
+	}
+}
+
+// ParamDefault returns the initial value shown in the prompt for a parameter.
+// Choice parameters without a default start on their first choice.
+func ParamDefault(p config.CommandParam) string {
+	if p.Default != "" {
+		return p.Default
+	}
+	if ParamType(p) == ParamChoice && len(p.Choices) > 0 {
+		return p.Choices[0]
+	}
+	return ""
+}
+
+// ValidateParamValue checks a single value against its declared type and returns the normalized value.
+// Paths get a leading ~ expanded, everything else is returned as typed.
+func ValidateParamValue(p config.CommandParam, value string) (string, error) {
+	if strings.ContainsRune(value, 0) {
+		return "", fmt.Errorf("%s: value contains a NUL byte", p.Name)
+	}
+
+	switch ParamType(p) {
+	case ParamNumber:
+		v := strings.TrimSpace(value)
+		if v == "" {
+			return "", fmt.Errorf("%s: a number is required", p.Name)
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", fmt.Errorf("%s: %q is not a number", p.Name, v)
+		}
+		return v, nil
+	case ParamChoice:
+		for _, c := range p.Choices {
+			if c == value {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("%s: %q is not one of %s", p.Name, value, strings.Join(p.Choices, ", "))
+	case ParamPath:
+		v := strings.TrimSpace(value)
+		if v == "" {
+			return "", fmt.Errorf("%s: a path is required", p.Name)
+		}
+		if v == "~" || strings.HasPrefix(v, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				v = filepath.Join(home, strings.TrimPrefix(v, "~"))
+			}
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// ResolveParamValues merges the provided values with the declared defaults and validates each one.
+// The result is keyed by parameter name and is safe to hand to ExpandParams.
+func ResolveParamValues(params []config.CommandParam, values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(params))
+	for _, p := range params {
+		if strings.TrimSpace(p.Name) == "" {
+			return nil, fmt.Errorf("parameter without a name")
+		}
+		if IsBuiltinVar(p.Name) {
+			return nil, fmt.Errorf("parameter %q shadows a built-in variable", p.Name)
+		}
+		raw, ok := values[p.Name]
+		if !ok {
+			raw = ParamDefault(p)
+		}
+		v, err := ValidateParamValue(p, raw)
+		if err != nil {
+			return nil, err
+		}
+		resolved[p.Name] = v
+	}
+	return resolved, nil
+}
+
+// ExpandParams replaces {{name}} placeholders for declared parameters and built-in variables
+// with their values, quoted for the given shell. Placeholders without a value are left alone.
+func ExpandParams(commandStr, shell string, values map[string]string) (string, error) {
+	var firstErr error
+	out := placeholderPattern.ReplaceAllStringFunc(commandStr, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		v, ok := values[name]
+		if !ok {
+			return match
+		}
+		quoted, err := QuoteForShell(shell, v)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", name, err)
+			}
+			return match
+		}
+		return quoted
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
+
+// QuoteForShell escapes a value so the given shell receives it as a single literal word.
+// The shell names match the ones understood by buildShellCmd.
+func QuoteForShell(shell, value string) (string, error) {
+	if strings.ContainsRune(value, 0) {
+		return "", fmt.Errorf("value contains a NUL byte")
+	}
+
+	switch shell {
+	case "fish":
+		// Inside fish single quotes only \\ and \' are escapes.
+		r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		return "'" + r.Replace(value) + "'", nil
+	case "pwsh", "powershell":
+		// PowerShell treats typographic single quotes as quotes too; doubling escapes all of them.
+		r := strings.NewReplacer("'", "''", "‘", "‘‘", "’", "’’", "‚", "‚‚", "‛", "‛‛")
+		return "'" + r.Replace(value) + "'", nil
+	case "cmd", "cmd.exe":
+		// cmd.exe has no reliable escape for these inside a quoted argument, so refuse them.
+		if strings.ContainsAny(value, "\"%\r\n") {
+			return "", fmt.Errorf("value contains characters that cannot be safely quoted for cmd (\", %%, newline)")
+		}
+		return `"` + value + `"`, nil
+	default:
+		// POSIX shells (bash, sh, zsh): close the quote, emit an escaped quote, reopen.
+		return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'", nil
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestQuoteForShell(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"posix plain", "bash", "hello world", `'hello world'`, false},
+		{"posix single quote", "zsh", "it's", `'it'\''s'`, false},
+		{"posix metachars stay literal", "sh", "$(rm -rf ~); `id`", `'$(rm -rf ~); ` + "`id`" + `'`, false},
+		{"fish escapes", "fish", `a\b'c`, `'a\\b\'c'`, false},
+		{"pwsh doubling", "pwsh", "it's", `'it''s'`, false},
+		{"cmd plain", "cmd", "C:\\Temp dir", `"C:\Temp dir"`, false},
+		{"cmd rejects quote", "cmd", `a"b`, "", true},
+		{"cmd rejects percent", "cmd", "%PATH%", "", true},
+		{"nul rejected", "bash", "a\x00b", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := QuoteForShell(tc.shell, tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpandParams(t *testing.T) {
+	values := map[string]string{"branch": "feat/x y", "n": "3"}
+	got, err := ExpandParams("git checkout {{branch}} && echo {{ n }} {{unknown}}", "bash", values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "git checkout 'feat/x y' && echo '3' {{unknown}}"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestResolveParamValues(t *testing.T) {
+	params := []config.CommandParam{
+		{Name: "env", Type: "choice", Choices: []string{"dev", "prod"}},
+		{Name: "count", Type: "number", Default: "1"},
+		{Name: "msg"},
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		got, err := ResolveParamValues(params, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got["env"] != "dev" || got["count"] != "1" || got["msg"] != "" {
+			t.Fatalf("unexpected defaults: %v", got)
+		}
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		if _, err := ResolveParamValues(params, map[string]string{"count": "abc"}); err == nil {
+			t.Fatal("expected error for non-numeric value")
+		}
+	})
+
+	t.Run("invalid choice", func(t *testing.T) {
+		if _, err := ResolveParamValues(params, map[string]string{"env": "staging"}); err == nil {
+			t.Fatal("expected error for value outside choices")
+		}
+	})
+
+	t.Run("shadows built-in", func(t *testing.T) {
+		shadow := []config.CommandParam{{Name: VarProfile}}
+		if _, err := ResolveParamValues(shadow, nil); err == nil {
+			t.Fatal("expected error for a param named like a built-in variable")
+		}
+	})
+}
+
+func TestRunCommandWith_InvalidParams(t *testing.T) {
+	oldPause, oldLook, oldCmd := pauseFn, lookPathFn, commandFn
+	defer func() { pauseFn, lookPathFn, commandFn = oldPause, oldLook, oldCmd }()
+
+	var paused bool
+	pauseFn = func(string) { paused = true }
+
+	cfg := config.Config{
+		DefaultShell: "bash",
+		Commands: []config.Command{{
+			Name:    "greet",
+			Command: "echo {{who}}",
+			Params:  []config.CommandParam{{Name: "who", Type: "number"}},
+		}},
+	}
+
+	// An invalid value must stop before anything is executed.
+	RunCommandWith(cfg, "greet", RunOptions{Params: map[string]string{"who": "; rm -rf /"}})
+	if !paused {
+		t.Fatal("expected invalid parameters to pause with an error")
+	}
+}
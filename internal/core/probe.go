@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+const (
+	defaultStatusInterval = 30 * time.Second
+	// minStatusInterval keeps a typo like "1ms" from turning a probe into a busy loop.
+	minStatusInterval = time.Second
+	// maxProbeTimeout bounds a single probe; shorter intervals bound it further.
+	maxProbeTimeout = 10 * time.Second
+	// maxParallelProbes is how many status commands may run at the same time.
+	maxParallelProbes = 4
+)
+
+// ProbeResult is the outcome of one run of a cell's status_command.
+type ProbeResult struct {
+	ExitCode int    // -1 if the probe never reported one
+	Line     string // First non-empty line of its output
+	Err      string // Start/wait error, e.g. a timeout
+	At       time.Time
+}
+
+// OK reports whether the probe exited cleanly.
+func (r ProbeResult) OK() bool {
+	return r.ExitCode == 0 && r.Err == ""
+}
+
+var (
+	probeSlots = make(chan struct{}, maxParallelProbes)
+
+	probeCacheMu sync.Mutex
+	probeCache   = map[string]ProbeResult{}
+)
+
+func probeCacheKey(profile string, c config.Command) string {
+	return strings.Join([]string{profile, c.Name, c.StatusCommand}, "\x00")
+}
+
+// StatusInterval returns how often the status_command of c runs.
+func StatusInterval(c config.Command) (time.Duration, error) {
+	raw := strings.TrimSpace(c.StatusInterval)
+	if raw == "" {
+		return defaultStatusInterval, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid status_interval %q", raw)
+	}
+	if d < minStatusInterval {
+		d = minStatusInterval
+	}
+	return d, nil
+}
+
+// LastProbe returns the latest result of c's status_command in profile. Results outlive the
+// TUI being rebuilt after a command, so cells keep their status.
+func LastProbe(profile string, c config.Command) (ProbeResult, bool) {
+	probeCacheMu.Lock()
+	defer probeCacheMu.Unlock()
+	r, ok := probeCache[probeCacheKey(profile, c)]
+	return r, ok
+}
+
+// ProbeDue reports whether c's status_command should run again.
+func ProbeDue(profile string, c config.Command, now time.Time) bool {
+	if strings.TrimSpace(c.StatusCommand) == "" {
+		return false
+	}
+	last, ok := LastProbe(profile, c)
+	if !ok {
+		return true
+	}
+	interval, err := StatusInterval(c)
+	if err != nil {
+		// Report the error once; there is nothing to retry.
+		return false
+	}
+	return now.Sub(last.At) >= interval
+}
+
+// RunProbe runs c's status_command where the cell runs, with its cwd and environment, and
+// records the result.
+// At most maxParallelProbes run at once; callers beyond that wait for a slot.
+func RunProbe(cfg config.Config, c config.Command, opts RunOptions) ProbeResult {
+	probeSlots <- struct{}{}
+	defer func() { <-probeSlots }()
+
+	result := ProbeResult{ExitCode: -1}
+	interval, err := StatusInterval(c)
+	if err == nil {
+		err = runProbe(cfg, c, opts, min(interval, maxProbeTimeout), &result)
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	result.At = time.Now()
+
+	probeCacheMu.Lock()
+	probeCache[probeCacheKey(opts.Profile, c)] = result
+	probeCacheMu.Unlock()
+	return result
+}
+
+func runProbe(cfg config.Config, c config.Command, opts RunOptions, timeout time.Duration, result *ProbeResult) error {
+	commandStr, err := ExpandParams(c.StatusCommand, cfg.DefaultShell, BuiltinVars(opts))
+	if err != nil {
+		return err
+	}
+	run, err := runCaptured(cfg, ResolveCommandSpec(&c, nil), opts, commandStr, timeout)
+	// A non-zero exit is the probe's answer, not an error.
+	result.ExitCode = run.exitCode
+	result.Line = firstLine(run.stdout)
+	if result.Line == "" {
+		result.Line = firstLine(run.stderr)
+	}
+	return err
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestRunProbe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := config.Config{DefaultShell: "sh"}
+
+	up := config.Command{Name: "Unit", StatusCommand: "printf '\\nactive\\nmore\\n'"}
+	if !ProbeDue("core", up, time.Now()) {
+		t.Fatal("expected a probe that never ran to be due")
+	}
+	if r := RunProbe(cfg, up, RunOptions{Profile: "core"}); !r.OK() || r.Line != "active" {
+		t.Fatalf("expected an ok probe with its first line, got %+v", r)
+	}
+	if ProbeDue("core", up, time.Now()) || !ProbeDue("core", up, time.Now().Add(31*time.Second)) {
+		t.Fatal("expected the probe to be due again after the default interval")
+	}
+	if r, ok := LastProbe("core", up); !ok || r.Line != "active" {
+		t.Fatalf("expected the result to be kept, got %+v", r)
+	}
+
+	pending := config.Command{Name: "Updates", StatusCommand: "echo '3 pending' >&2; exit 1"}
+	if r := RunProbe(cfg, pending, RunOptions{}); r.OK() || r.ExitCode != 1 || r.Err != "" || r.Line != "3 pending" {
+		t.Fatalf("expected a failed probe with its stderr line, got %+v", r)
+	}
+
+	slow := config.Command{Name: "Slow", StatusCommand: "sleep 5", StatusInterval: "1s"}
+	start := time.Now()
+	if r := RunProbe(cfg, slow, RunOptions{}); !strings.Contains(r.Err, "timed out") || time.Since(start) > 4*time.Second {
+		t.Fatalf("expected the probe to be cut off at its interval, got %+v", r)
+	}
+
+	bad := config.Command{Name: "Bad", StatusCommand: "true", StatusInterval: "often"}
+	if r := RunProbe(cfg, bad, RunOptions{}); !strings.Contains(r.Err, "status_interval") {
+		t.Fatalf("expected an interval error, got %+v", r)
+	}
+}
+
+func TestRunProbe_BoundedParallelism(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := config.Config{DefaultShell: "sh"}
+
+	// Twice as many probes as slots need at least two rounds.
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2*maxParallelProbes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			RunProbe(cfg, config.Command{Name: fmt.Sprint("p", i), StatusCommand: "sleep 0.3"}, RunOptions{})
+		}(i)
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 600*time.Millisecond {
+		t.Fatalf("expected at most %d probes at a time, all finished in %s", maxParallelProbes, elapsed)
+	}
+}
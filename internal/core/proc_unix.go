@@ -0,0 +1,56 @@
+//go:build !windows
+
+package core
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/term"
+)
+
+// detachedSysProcAttr starts the child in its own session so it survives the TUI
+// being torn down and can be signalled as a group.
+func detachedSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// setProcessGroup starts cmd in its own process group so it can be signalled as a whole.
+// With foreground set and a terminal on stdin, the group also becomes the terminal's
+// foreground group; the returned func hands the terminal back to drako afterwards.
+func setProcessGroup(cmd *exec.Cmd, foreground bool) (restore func()) {
+	fd := int(os.Stdin.Fd())
+	if !foreground || !term.IsTerminal(fd) {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		return func() {}
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Foreground: true, Ctty: fd}
+	return func() { reclaimTerminal(fd) }
+}
+
+// reclaimTerminal makes drako's process group the terminal's foreground group again.
+// drako is a background process at that point, so SIGTTOU must be ignored or the ioctl would stop it.
+func reclaimTerminal(fd int) {
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	pgrp := int32(syscall.Getpgrp())
+	syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCSPGRP), uintptr(unsafe.Pointer(&pgrp)))
+}
+
+// interruptProcessGroup sends the whole process group led by p a Ctrl+C.
+func interruptProcessGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGINT)
+}
+
+// terminateProcessGroup asks the whole process group led by p to stop.
+func terminateProcessGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGTERM)
+}
+
+// killProcessGroup forcefully stops the whole process group led by p.
+func killProcessGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package core
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// detachedSysProcAttr starts the child in a new process group so console
+// signals aimed at drako do not reach it.
+func detachedSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// setProcessGroup leaves cmd in drako's console; process groups are only used for
+// signalling on Unix, and every escalation step below is a hard kill here.
+func setProcessGroup(cmd *exec.Cmd, foreground bool) (restore func()) {
+	return func() {}
+}
+
+// interruptProcessGroup stops the process. Windows cannot send Ctrl+C to another console process group.
+func interruptProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// terminateProcessGroup stops the process. Windows has no SIGTERM, so this is a hard kill.
+func terminateProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// killProcessGroup stops the process.
+func killProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,295 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// TargetHost returns the host the active profile's commands run on, or "" to run locally.
+// A profile with several hosts needs the caller to pick one (RunOptions.Host).
+func TargetHost(cfg config.Config, opts RunOptions) (string, error) {
+	switch {
+	case len(cfg.Hosts) == 0:
+		return "", nil
+	case opts.Host != "":
+		for _, h := range cfg.Hosts {
+			if h == opts.Host {
+				return h, nil
+			}
+		}
+		return "", fmt.Errorf("host %q is not declared by the profile", opts.Host)
+	case len(cfg.Hosts) == 1:
+		return cfg.Hosts[0], nil
+	default:
+		return "", fmt.Errorf("profile has several hosts, pick one of %s", strings.Join(cfg.Hosts, ", "))
+	}
+}
+
+// attachTerminal reports whether a remote or container command should get a terminal:
+// only when stdin is attached and is one.
+func attachTerminal(opts RunOptions) bool {
+	return !opts.detached && stdinIsTerminal()
+}
+
+// shellCommand builds the *exec.Cmd for an expanded command string: locally through the
+// configured shell, wrapped in docker/podman exec for a container target, or wrapped in
+// ssh when the profile targets a host.
+// stepCwd is the cwd of a pipeline step, resolved against the command's own cwd.
+func shellCommand(cfg config.Config, spec CommandSpec, opts RunOptions, commandStr, stepCwd string) (*exec.Cmd, error) {
+	host, err := TargetHost(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	if target := EffectiveContainer(cfg, spec); target != nil {
+		if host != "" {
+			return nil, fmt.Errorf("container %s cannot be combined with remote host %s", target.Name, host)
+		}
+		return containerCommand(cfg, spec, opts, *target, commandStr, stepCwd)
+	}
+	if host != "" {
+		return remoteCommand(cfg, spec, opts, host, commandStr, stepCwd)
+	}
+
+	cmd := buildShellCmd(cfg.DefaultShell, commandStr)
+	if err := applyCommandContext(cmd, cfg, spec, opts); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(stepCwd) != "" {
+		base := cmd.Dir
+		if base == "" {
+			if base, err = os.Getwd(); err != nil {
+				return nil, err
+			}
+		}
+		dir, err := ResolveWorkDir(stepCwd, base, opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Dir = dir
+	}
+	return cmd, nil
+}
+
+// detachedShellCommand is shellCommand for the commands drako runs for itself (items_command,
+// status_command, requires.check): on the profile's host or container like the cell would be,
+// but without stdin or a terminal.
+func detachedShellCommand(cfg config.Config, spec CommandSpec, opts RunOptions, commandStr string) (*exec.Cmd, error) {
+	opts.detached = true
+	return shellCommand(cfg, spec, opts, commandStr, "")
+}
+
+// capturedRun is the output of a command run by runCaptured and how it exited.
+type capturedRun struct {
+	stdout, stderr string
+	exitCode       int // -1 if the command did not exit on its own
+}
+
+// runCaptured runs commandStr through detachedShellCommand with its output captured, stopping it
+// after timeout. A non-zero exit is reported in exitCode only; the error means the command could
+// not start or timed out.
+func runCaptured(cfg config.Config, spec CommandSpec, opts RunOptions, commandStr string, timeout time.Duration) (capturedRun, error) {
+	run := capturedRun{exitCode: -1}
+	cmd, err := detachedShellCommand(cfg, spec, opts, commandStr)
+	if err != nil {
+		return run, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// The TUI keeps the terminal; the command runs in its own process group.
+	err = runWithTimeout(cmd, timeout, false)
+	run.stdout, run.stderr = stdout.String(), stderr.String()
+	if cmd.ProcessState != nil {
+		run.exitCode = cmd.ProcessState.ExitCode()
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return run, nil
+	}
+	return run, err
+}
+
+// envNamePattern matches variable names a POSIX shell can assign.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// remoteEnvScript (a format string taking the upload and the cleanup command) prints the
+// variables named in its arguments up to "--" as sh assignments, pipes them into the upload
+// command and then runs the arguments after "--". Values are single-quoted; the x keeps trailing
+// newlines. If ssh itself fails (255), the uploaded file may never have been loaded, so the
+// cleanup command removes it.
+const remoteEnvScript = `for k; do
+	[ "$k" = -- ] && break
+	eval "v=\${$k}"
+	e=$(printf '%%sx' "$v" | sed "s/'/'\\\\''/g"); e=${e%%x}
+	printf "%%s='%%s'\n" "$k" "$e"
+done | %s || exit 255
+while [ "$1" != -- ]; do shift; done
+shift
+"$@"
+s=$?
+[ "$s" -ne 255 ] || %s >/dev/null 2>&1
+exit "$s"`
+
+// remoteLoadEnv is the first thing the remote side runs when there is an env file ($1): it reads
+// and removes the file before anything else can fail, then changes to $2 (if set) and runs the rest.
+const remoteLoadEnv = `'e=$(cat "$1"); rm -f "$1"; set -a; eval "$e"; set +a; [ -z "$2" ] || cd "$2" || exit; shift 2; exec "$@"'`
+
+// remoteCommand wraps a command string in ssh for host. The remote side runs it with
+// the profile's shell, in the command's cwd (a remote path) and with its env/env_file values.
+// The env policy applies to the local ssh process.
+func remoteCommand(cfg config.Config, spec CommandSpec, opts RunOptions, host, commandStr, stepCwd string) (*exec.Cmd, error) {
+	dir := remoteDir(strings.TrimSpace(spec.Cwd), strings.TrimSpace(stepCwd))
+	if dir == assetsPrefix || strings.HasPrefix(dir, assetsPrefix+"/") {
+		return nil, fmt.Errorf("cwd %q: %s is not available on remote hosts", dir, assetsPrefix)
+	}
+	base, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := CommandEnvOverrides(spec, base, opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		if !envNamePattern.MatchString(k) {
+			return nil, fmt.Errorf("env %q: not a valid variable name for the remote shell", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	envFile := ""
+	if len(keys) > 0 {
+		envFile = "~/.drako-env-" + randomSuffix()
+	}
+	remote, err := remoteCommandLine(cfg.DefaultShell, commandStr, dir, envFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if attachTerminal(opts) {
+		args = append(args, "-t")
+	} else if opts.detached {
+		args = append(args, "-n")
+	}
+	args = append(args, cfg.SSHOptions...)
+	args = append(args, "--", host, remote)
+	if envFile == "" {
+		cmd := exec.Command("ssh", args...)
+		cmd.Env = CommandEnv(cfg)
+		return cmd, nil
+	}
+
+	// The values go to the remote side through the stdin of a first ssh call that writes
+	// envFile; only their names are on a command line. The wrapper reads them from its own
+	// environment, so a history replay (which applies env again) works the same way.
+	upload, err := sshLine(cfg, host, `sh -c 'umask 077 && cat > "$1"' drako `+envFile)
+	if err != nil {
+		return nil, err
+	}
+	cleanup, err := sshLine(cfg, host, "rm -f "+envFile)
+	if err != nil {
+		return nil, err
+	}
+	wrapper := append([]string{"-c", fmt.Sprintf(remoteEnvScript, upload, cleanup), "drako"}, keys...)
+	wrapper = append(append(wrapper, "--", "ssh"), args...)
+	cmd := exec.Command("sh", wrapper...)
+	cmd.Env = CommandEnv(cfg)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+overrides[k])
+	}
+	return cmd, nil
+}
+
+// sshLine renders a non-interactive ssh call of remote on host as one sh command line.
+func sshLine(cfg config.Config, host, remote string) (string, error) {
+	argv := append([]string{"ssh", "-T"}, cfg.SSHOptions...)
+	argv = append(argv, "--", host, remote)
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		q, err := QuoteForShell("sh", arg)
+		if err != nil {
+			return "", err
+		}
+		quoted[i] = q
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// remoteDir joins a step cwd onto the command cwd using remote (slash) semantics.
+func remoteDir(cwd, stepCwd string) string {
+	switch {
+	case stepCwd == "":
+		return cwd
+	case cwd == "", path.IsAbs(stepCwd), stepCwd == "~", strings.HasPrefix(stepCwd, "~/"):
+		return stepCwd
+	default:
+		return path.Join(cwd, stepCwd)
+	}
+}
+
+// remoteCommandLine renders the string ssh hands to the remote login shell. Every word is
+// quoted for a POSIX shell, so the command string reaches the configured shell unchanged.
+// With envFile, sh loads and removes that file of assignments first, even if dir does not exist.
+func remoteCommandLine(shell, commandStr, dir, envFile string) (string, error) {
+	var words []string
+	if envFile != "" {
+		file, err := quoteRemotePath(envFile)
+		if err != nil {
+			return "", err
+		}
+		cd := "''"
+		if dir != "" {
+			if cd, err = quoteRemotePath(dir); err != nil {
+				return "", err
+			}
+		}
+		words = append(words, "sh", "-c", remoteLoadEnv, "drako", file, cd)
+	} else if dir != "" {
+		q, err := quoteRemotePath(dir)
+		if err != nil {
+			return "", err
+		}
+		words = append(words, "cd", q, "&&")
+	}
+	for _, arg := range buildShellCmd(shell, commandStr).Args {
+		q, err := QuoteForShell("sh", arg)
+		if err != nil {
+			return "", err
+		}
+		words = append(words, q)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// randomSuffix returns a short random hex string for temporary file names.
+func randomSuffix() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// quoteRemotePath quotes a remote directory but leaves a leading ~ for the remote shell to expand.
+func quoteRemotePath(dir string) (string, error) {
+	if dir == "~" {
+		return dir, nil
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		q, err := QuoteForShell("sh", rest)
+		return "~/" + q, err
+	}
+	return QuoteForShell("sh", dir)
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestTargetHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []string
+		pick    string
+		want    string
+		wantErr bool
+	}{
+		{"local", nil, "", "", false},
+		{"single host", []string{"web1"}, "", "web1", false},
+		{"picked", []string{"web1", "web2"}, "web2", "web2", false},
+		{"not picked", []string{"web1", "web2"}, "", "", true},
+		{"unknown pick", []string{"web1"}, "db1", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TargetHost(config.Config{Hosts: tt.hosts}, RunOptions{Host: tt.pick})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("host = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteCommandLine(t *testing.T) {
+	got, err := remoteCommandLine("bash", "echo $HOME", "~/my app", "~/.drako-env-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "sh -c " + remoteLoadEnv + ` drako ~/'.drako-env-1' ~/'my app' 'bash' '-lc' 'echo $HOME'`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	got, err = remoteCommandLine("bash", "echo $HOME", "~/my app", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `cd ~/'my app' && 'bash' '-lc' 'echo $HOME'`; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	got, err = remoteCommandLine("sh", "uptime", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "'sh' ") || strings.Contains(got, "cd ") {
+		t.Errorf("unexpected command line %s", got)
+	}
+}
+
+func TestRemoteDir(t *testing.T) {
+	tests := []struct{ cwd, step, want string }{
+		{"/srv/app", "", "/srv/app"},
+		{"/srv/app", "web", "/srv/app/web"},
+		{"/srv/app", "/tmp", "/tmp"},
+		{"", "web", "web"},
+		{"/srv/app", "~/x", "~/x"},
+	}
+	for _, tt := range tests {
+		if got := remoteDir(tt.cwd, tt.step); got != tt.want {
+			t.Errorf("remoteDir(%q, %q) = %q, want %q", tt.cwd, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareCommand_Remote(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	oldTerminal := stdinIsTerminal
+	defer func() { stdinIsTerminal = oldTerminal }()
+	stdinIsTerminal = func() bool { return true }
+	cfg := config.Config{
+		DefaultShell: "bash",
+		Hosts:        []string{"web1"},
+		SSHOptions:   []string{"-p", "2222"},
+		Commands: []config.Command{
+			{Name: "Uptime", Command: "uptime", Cwd: "/var/log"},
+			{Name: "Logs", Command: "tail -n 50 app.log", Env: map[string]string{"LANG": "C", "TOKEN": "s3cret"}},
+			{Name: "Assets", Command: "ls", Cwd: "@assets"},
+		},
+	}
+
+	cmd, _, err := prepareCommand(cfg, "Uptime", RunOptions{Profile: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ssh", "-t", "-p", "2222", "--", "web1", `cd '/var/log' && 'bash' '-lc' 'uptime'`}
+	if strings.Join(cmd.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+	if cmd.Dir != "" {
+		t.Errorf("expected local ssh to run in drako's cwd, got %q", cmd.Dir)
+	}
+
+	// Background jobs have no stdin: no terminal, and ssh must not read one
+	cmd, _, err = prepareCommand(cfg, "Uptime", RunOptions{Profile: "ops", detached: true})
+	if err != nil || cmd.Args[1] != "-n" {
+		t.Errorf("expected ssh -n for a detached run, got %q (%v)", cmd.Args, err)
+	}
+
+	// Env values stay off the command line; only their names are there
+	cmd, _, err = prepareCommand(cfg, "Logs", RunOptions{Profile: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := strings.Join(cmd.Args, " ")
+	if strings.Contains(line, "s3cret") || strings.Contains(line, "LANG=C") {
+		t.Errorf("env values leaked onto argv: %q", cmd.Args)
+	}
+	remote := regexp.MustCompile(`^sh -c ` + regexp.QuoteMeta(remoteLoadEnv) + ` drako ~/'\.drako-env-[0-9a-f]{16}' '' 'bash' '-lc' 'tail -n 50 app\.log'$`)
+	if cmd.Args[0] != "sh" || !strings.Contains(line, " drako LANG TOKEN -- ssh -t -p 2222 -- web1 ") || !remote.MatchString(cmd.Args[len(cmd.Args)-1]) {
+		t.Errorf("unexpected wrapper %q", cmd.Args)
+	}
+	if !slices.Contains(cmd.Env, "TOKEN=s3cret") {
+		t.Error("expected the values in the wrapper's environment")
+	}
+
+	if _, _, err := prepareCommand(cfg, "Assets", RunOptions{Profile: "ops"}); err == nil {
+		t.Error("expected @assets cwd to be rejected for remote hosts")
+	}
+}
+
+func TestRemoteCommand_UploadsEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	home := t.TempDir()
+	bin := t.TempDir()
+	// A fake ssh runs the remote command locally, with home standing in for the remote home.
+	fake := "#!/bin/sh\nwhile [ \"$1\" != -- ]; do shift; done\nshift 2\nHOME=" + home + " exec sh -c \"$1\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	value := "it's \"quoted\" $HOME\nand two lines\n"
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Hosts:        []string{"web1"},
+		Commands:     []config.Command{{Name: "Show", Command: `printf '%s|' "$TOKEN" "$PLAIN"`, Env: map[string]string{"TOKEN": value, "PLAIN": "x"}}},
+	}
+	cmd, _, err := prepareCommand(cfg, "Show", RunOptions{detached: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("remote run failed: %v (%s)", err, out)
+	}
+	if string(out) != value+"|x|" {
+		t.Errorf("remote side saw %q, want %q", out, value+"|x|")
+	}
+	if left, _ := filepath.Glob(filepath.Join(home, ".drako-env-*")); len(left) != 0 {
+		t.Errorf("expected the env file to be removed, found %q", left)
+	}
+
+	// A remote cwd that does not exist fails the command, but the file is gone already
+	cfg.Commands[0].Cwd = filepath.Join(home, "missing")
+	cmd, _, err = prepareCommand(cfg, "Show", RunOptions{detached: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Errorf("expected the missing cwd to fail the command, got %q", out)
+	}
+	if left, _ := filepath.Glob(filepath.Join(home, ".drako-env-*")); len(left) != 0 {
+		t.Errorf("expected the env file to be removed after a failed cd, found %q", left)
+	}
+
+	// If the second ssh call fails, the local side removes the uploaded file
+	failing := "#!/bin/sh\nwhile [ \"$1\" != -- ]; do shift; done\nshift 2\ncase \"$1\" in 'sh -c '\\''umask'*|'rm -f '*) ;; *) exit 255 ;; esac\nHOME=" + home + " exec sh -c \"$1\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(failing), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Commands[0].Cwd = ""
+	cmd, _, err = prepareCommand(cfg, "Show", RunOptions{detached: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(); cmd.ProcessState == nil || cmd.ProcessState.ExitCode() != 255 {
+		t.Errorf("expected ssh's 255 to come through, got %v", err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(home, ".drako-env-*")); len(left) != 0 {
+		t.Errorf("expected the env file to be cleaned up after ssh failed, found %q", left)
+	}
+}
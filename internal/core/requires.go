@@ -0,0 +1,185 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+const (
+	// requirementCheckTimeout bounds a requires.check command.
+	requirementCheckTimeout = 3 * time.Second
+	// requirementCheckCache is how long a check result counts as fresh; older results are still
+	// shown until the check has run again.
+	requirementCheckCache = time.Minute
+)
+
+// RequirementChecking is the Unmet reason of a cell whose requires.check has not reported yet.
+const RequirementChecking = "checking requirements"
+
+var runtimeTargetFn = config.RuntimeTarget
+
+type requirementCheck struct {
+	unmet string
+	at    time.Time
+}
+
+var (
+	requirementChecksMu sync.Mutex
+	requirementChecks   = map[string]requirementCheck{}
+)
+
+// ApplyRequirements returns cfg's commands with Unmet set on every cell and item that cannot run
+// on this machine. cfg itself is left untouched. Check commands are not run here: a cell whose
+// check has never reported is marked RequirementChecking until RunRequirementCheck has run it.
+func ApplyRequirements(cfg config.Config) []config.Command {
+	commands := config.CopyCommands(cfg.Commands)
+	for i := range commands {
+		c := &commands[i]
+		c.Unmet = unmet(cfg, c.Requires, c.Command, c.OS, cachedRequirementCheck)
+		if len(c.Items) == 0 {
+			continue
+		}
+		items := make([]config.CommandItem, len(c.Items))
+		copy(items, c.Items)
+		for j := range items {
+			items[j].Unmet = unmet(cfg, items[j].Requires, items[j].Command, items[j].OS, cachedRequirementCheck)
+		}
+		c.Items = items
+	}
+	return commands
+}
+
+// PendingRequirementChecks lists the check commands of cfg's cells and items without a fresh result.
+func PendingRequirementChecks(cfg config.Config) []string {
+	var pending []string
+	seen := map[string]bool{}
+	add := func(req *config.Requirement) {
+		if req == nil || strings.TrimSpace(req.Check) == "" || seen[req.Check] {
+			return
+		}
+		seen[req.Check] = true
+		requirementChecksMu.Lock()
+		cached, ok := requirementChecks[requirementCheckKey(cfg, req.Check)]
+		requirementChecksMu.Unlock()
+		if !ok || time.Since(cached.at) >= requirementCheckCache {
+			pending = append(pending, req.Check)
+		}
+	}
+	for _, c := range cfg.Commands {
+		add(c.Requires)
+		for _, item := range c.Items {
+			add(item.Requires)
+		}
+	}
+	return pending
+}
+
+// CellUnmet explains why a cell, or an item of it, cannot run here; "" means it can.
+// Unlike ApplyRequirements it runs a check command that has no fresh result.
+func CellUnmet(cfg config.Config, parent *config.Command, item *config.CommandItem) string {
+	if reason := unmet(cfg, parent.Requires, parent.Command, parent.OS, runRequirementCheck); reason != "" || item == nil {
+		return reason
+	}
+	return unmet(cfg, item.Requires, item.Command, item.OS, runRequirementCheck)
+}
+
+// unmet covers an os table without a variant for this machine (and no plain command to fall
+// back on) as well as requires.
+func unmet(cfg config.Config, req *config.Requirement, command string, variants map[string]string, check func(cfg config.Config, check string) string) string {
+	if len(variants) > 0 && strings.TrimSpace(command) == "" {
+		return fmt.Sprintf("no os variant for %s", runtimeTargetFn())
+	}
+	return unmetRequirement(cfg, req, check)
+}
+
+// UnmetRequirement explains why req is not met, or returns "" when it is (or req is nil).
+// OS targets are checked first, then binaries, then the check command, which runs where cfg
+// runs its commands (locally, on its host or in its container).
+func UnmetRequirement(cfg config.Config, req *config.Requirement) string {
+	return unmetRequirement(cfg, req, runRequirementCheck)
+}
+
+func unmetRequirement(cfg config.Config, req *config.Requirement, check func(cfg config.Config, check string) string) string {
+	if req == nil {
+		return ""
+	}
+	if len(req.OS) > 0 {
+		target := runtimeTargetFn()
+		matched := false
+		for _, want := range req.OS {
+			want = strings.ToLower(strings.TrimSpace(want))
+			if want == target || (want == "linux" && strings.HasPrefix(target, "linux_")) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("only for %s (this is %s)", strings.Join(req.OS, ", "), target)
+		}
+	}
+	var missing []string
+	for _, bin := range req.Bins {
+		if _, err := lookPathFn(bin); err != nil {
+			missing = append(missing, bin)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("needs %s on PATH", strings.Join(missing, ", "))
+	}
+	if strings.TrimSpace(req.Check) != "" {
+		return check(cfg, req.Check)
+	}
+	return ""
+}
+
+// RunRequirementCheck runs one of cfg's check commands (see PendingRequirementChecks) and keeps
+// the result for ApplyRequirements. It blocks for up to requirementCheckTimeout.
+func RunRequirementCheck(cfg config.Config, check string) {
+	runRequirementCheck(cfg, check)
+}
+
+// requirementCheckKey tells apart the same check run through another shell or on another target.
+func requirementCheckKey(cfg config.Config, check string) string {
+	target := strings.Join(cfg.Hosts, ",")
+	if cfg.Container != nil {
+		target = cfg.Container.Runtime + ":" + cfg.Container.Name
+	}
+	return cfg.DefaultShell + "\x00" + target + "\x00" + check
+}
+
+// cachedRequirementCheck returns the last result of a check, however old, without running it.
+func cachedRequirementCheck(cfg config.Config, check string) string {
+	requirementChecksMu.Lock()
+	defer requirementChecksMu.Unlock()
+	if cached, ok := requirementChecks[requirementCheckKey(cfg, check)]; ok {
+		return cached.unmet
+	}
+	return RequirementChecking
+}
+
+// runRequirementCheck runs a requires.check command, reusing a recent result.
+func runRequirementCheck(cfg config.Config, check string) string {
+	key := requirementCheckKey(cfg, check)
+	requirementChecksMu.Lock()
+	cached, ok := requirementChecks[key]
+	requirementChecksMu.Unlock()
+	if ok && time.Since(cached.at) < requirementCheckCache {
+		return cached.unmet
+	}
+
+	unmet := ""
+	if run, err := runCaptured(cfg, CommandSpec{}, RunOptions{}, check, requirementCheckTimeout); err != nil {
+		unmet = fmt.Sprintf("check %q: %v", check, err)
+	} else if run.exitCode != 0 {
+		unmet = fmt.Sprintf("check %q exited with %d", check, run.exitCode)
+	}
+
+	requirementChecksMu.Lock()
+	requirementChecks[key] = requirementCheck{unmet: unmet, at: time.Now()}
+	requirementChecksMu.Unlock()
+	return unmet
+}
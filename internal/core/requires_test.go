@@ -0,0 +1,111 @@
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestUnmetRequirement(t *testing.T) {
+	oldTarget, oldLookPath := runtimeTargetFn, lookPathFn
+	defer func() { runtimeTargetFn, lookPathFn = oldTarget, oldLookPath }()
+	runtimeTargetFn = func() string { return "linux_arch" }
+	lookPathFn = func(name string) (string, error) {
+		if name == "docker" {
+			return "/usr/bin/docker", nil
+		}
+		return "", fmt.Errorf("not found")
+	}
+
+	tests := []struct {
+		name string
+		req  *config.Requirement
+		want string // substring of the reason; empty means met
+	}{
+		{"no requirement", nil, ""},
+		{"exact target", &config.Requirement{OS: []string{"macos", "linux_arch"}}, ""},
+		{"any linux", &config.Requirement{OS: []string{"linux"}}, ""},
+		{"other os", &config.Requirement{OS: []string{"macos", "windows"}}, "only for macos, windows (this is linux_arch)"},
+		{"binary present", &config.Requirement{Bins: []string{"docker"}}, ""},
+		{"binaries missing", &config.Requirement{Bins: []string{"docker", "kubectl", "helm"}}, "needs kubectl, helm on PATH"},
+	}
+	for _, tt := range tests {
+		got := UnmetRequirement(config.Config{DefaultShell: "sh"}, tt.req)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if got := UnmetRequirement(config.Config{DefaultShell: "sh"}, &config.Requirement{Check: "exit 0"}); got != "" {
+		t.Errorf("expected a passing check to be met, got %q", got)
+	}
+	if got := UnmetRequirement(config.Config{DefaultShell: "sh"}, &config.Requirement{Check: "exit 2"}); !strings.Contains(got, "exited with 2") {
+		t.Errorf("expected the check's exit code, got %q", got)
+	}
+}
+
+func TestApplyRequirements(t *testing.T) {
+	oldTarget := runtimeTargetFn
+	defer func() { runtimeTargetFn = oldTarget }()
+	runtimeTargetFn = func() string { return "windows" }
+
+	cfg := config.Config{Commands: []config.Command{
+		{Name: "Brew", Requires: &config.Requirement{OS: []string{"macos"}, Hide: true}},
+		{Name: "Brew Update", OS: map[string]string{"macos": "brew upgrade"}},
+		{Name: "Tools", Items: []config.CommandItem{
+			{Name: "PATH", Requires: &config.Requirement{OS: []string{"linux", "windows"}}},
+			{Name: "Shell rc", Requires: &config.Requirement{OS: []string{"linux"}}},
+		}},
+	}}
+
+	commands := ApplyRequirements(cfg)
+	if commands[0].Unmet == "" || commands[2].Unmet != "" {
+		t.Fatalf("expected only Brew to be unmet, got %+v", commands)
+	}
+	if commands[1].Unmet != "no os variant for windows" {
+		t.Fatalf("expected a missing os variant to be unmet, got %q", commands[1].Unmet)
+	}
+	if commands[2].Items[0].Unmet != "" || commands[2].Items[1].Unmet == "" {
+		t.Fatalf("expected only the shell rc item to be unmet, got %+v", commands[2].Items)
+	}
+	if cfg.Commands[0].Unmet != "" || cfg.Commands[2].Items[1].Unmet != "" {
+		t.Fatal("expected the original config to be left untouched")
+	}
+}
+
+func TestApplyRequirements_ChecksRunSeparately(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	cfg := config.Config{DefaultShell: "sh", Commands: []config.Command{
+		{Name: "VPN", Requires: &config.Requirement{Check: "exit 0 # vpn"}},
+		{Name: "Tools", Items: []config.CommandItem{
+			{Name: "Lint", Requires: &config.Requirement{Check: "exit 1 # lint"}},
+		}},
+	}}
+
+	commands := ApplyRequirements(cfg)
+	if commands[0].Unmet != RequirementChecking || commands[1].Items[0].Unmet != RequirementChecking {
+		t.Fatalf("expected cells to be checking before their checks ran, got %+v", commands)
+	}
+	pending := PendingRequirementChecks(cfg)
+	if len(pending) != 2 {
+		t.Fatalf("expected two pending checks, got %q", pending)
+	}
+	for _, check := range pending {
+		RunRequirementCheck(cfg, check)
+	}
+	if got := PendingRequirementChecks(cfg); len(got) != 0 {
+		t.Fatalf("expected no pending checks after running them, got %q", got)
+	}
+	commands = ApplyRequirements(cfg)
+	if commands[0].Unmet != "" || !strings.Contains(commands[1].Items[0].Unmet, "exited with 1") {
+		t.Fatalf("expected the check results, got %q and %q", commands[0].Unmet, commands[1].Items[0].Unmet)
+	}
+}
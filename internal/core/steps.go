@@ -0,0 +1,157 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// Outcomes of a single step in a multi-step command.
+const (
+	StepOK      = "ok"
+	StepFailed  = "failed"
+	StepSkipped = "skipped"
+)
+
+// StepResult is the outcome of one step, as shown in the summary after a pipeline.
+type StepResult struct {
+	Name     string
+	Status   string
+	Duration time.Duration
+	Err      error
+}
+
+// StepName returns the label of the i-th step (zero-based); unnamed steps are numbered.
+func StepName(step config.CommandStep, i int) string {
+	if name := strings.TrimSpace(step.Name); name != "" {
+		return name
+	}
+	return fmt.Sprintf("step %d", i+1)
+}
+
+// prepareStep builds the command for one step. Steps share the cell's parameters, env and
+// timeout; a step cwd is resolved against the cell's working directory.
+func prepareStep(cfg config.Config, spec CommandSpec, step config.CommandStep, opts RunOptions, values map[string]string) (*exec.Cmd, error) {
+	if strings.TrimSpace(step.Command) == "" {
+		return nil, errNoCommandConfigured
+	}
+	commandStr, err := ExpandParams(step.Command, cfg.DefaultShell, values)
+	if err != nil {
+		return nil, err
+	}
+	return shellCommand(cfg, spec, opts, commandStr, step.Cwd)
+}
+
+// runPipeline runs the steps of a multi-step command in order, each with a header, and
+// prints a per-step summary at the end. A failing step skips the remaining ones unless
+// it has continue_on_error. Every step is recorded in history on its own.
+func runPipeline(cfg config.Config, spec CommandSpec, opts RunOptions) {
+	timeout, err := CommandTimeout(cfg, spec)
+	var values map[string]string
+	if err == nil {
+		values, err = commandValues(spec, opts)
+	}
+	if err != nil {
+		log.Printf("Could not prepare %s: %v", spec.Name, err)
+		fmt.Printf("\n--- Command Not Started ---\n")
+		fmt.Printf("Command: '%s'\n", spec.Name)
+		fmt.Printf("Error: %v\n", err)
+		pauseFn("\nPress any key to return to the application.")
+		return
+	}
+
+	autoClose := boolOrDefault(spec.AutoCloseExecution, true)
+	debug := boolOrDefault(spec.DebugExecution, false)
+
+	results := make([]StepResult, len(spec.Steps))
+	failed, stopped := false, false
+	started := time.Now()
+	for i, step := range spec.Steps {
+		results[i] = StepResult{Name: StepName(step, i), Status: StepSkipped}
+		if stopped {
+			continue
+		}
+		fmt.Printf("\n--- Step %d/%d: %s ---\n", i+1, len(spec.Steps), results[i].Name)
+
+		start := time.Now()
+		err := runStep(cfg, spec, step, results[i].Name, opts, values, timeout, debug)
+		results[i].Duration = time.Since(start)
+		results[i].Status = StepOK
+		if err != nil {
+			results[i].Status = StepFailed
+			results[i].Err = err
+			failed = true
+			fmt.Printf("Error: %v\n", err)
+			stopped = !step.ContinueOnError
+		}
+	}
+
+	fmt.Print(FormatStepSummary(spec.Name, results))
+	var pipelineErr error
+	if failed {
+		pipelineErr = errors.New("a step failed")
+	}
+	notifyDone(cfg, spec, 0, pipelineErr, time.Since(started))
+	if failed || !autoClose || debug {
+		pauseFn("\nPress any key to return to the application.")
+	}
+}
+
+// runStep prepares and runs one step, recording it in history.
+func runStep(cfg config.Config, spec CommandSpec, step config.CommandStep, name string, opts RunOptions, values map[string]string, timeout time.Duration, debug bool) error {
+	cmd, err := prepareStep(cfg, spec, step, opts, values)
+	if err != nil {
+		return err
+	}
+
+	entry := newHistoryEntry(cfg, opts, spec, cmd, ModeLive)
+	entry.Step = name
+	if debug {
+		entry.Mode = ModeDebug
+		entry.Output, err = runDebug(cmd, spec.Name+" "+name, timeout)
+	} else {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = runWithTimeout(cmd, timeout, true)
+	}
+	recordHistory(finishHistoryEntry(entry, cmd, err))
+	return err
+}
+
+// FormatStepSummary renders the table printed after a multi-step command.
+func FormatStepSummary(name string, results []StepResult) string {
+	width := 0
+	for _, r := range results {
+		if n := len([]rune(r.Name)); n > width {
+			width = n
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n--- Summary: %s ---\n", name)
+	for i, r := range results {
+		mark := "✔"
+		switch r.Status {
+		case StepFailed:
+			mark = "✘"
+		case StepSkipped:
+			mark = "-"
+		}
+		fmt.Fprintf(&b, "%s %d. %-*s  %-7s", mark, i+1, width, r.Name, r.Status)
+		if r.Status != StepSkipped {
+			fmt.Fprintf(&b, "  %s", r.Duration.Round(100*time.Millisecond))
+		}
+		if r.Err != nil {
+			fmt.Fprintf(&b, "  (%v)", r.Err)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestRunCommand_Steps(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	paused := false
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) { paused = true }
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{{
+			Name: "Deploy",
+			Cwd:  dir,
+			Steps: []config.CommandStep{
+				{Name: "prepare", Command: "touch prepared", Cwd: "sub"},
+				{Name: "lint", Command: "exit 1", ContinueOnError: true},
+				{Command: "touch built"},
+				{Name: "test", Command: "exit 2"},
+				{Name: "publish", Command: "touch published"},
+			},
+		}},
+	}
+	RunCommandWith(cfg, "Deploy", RunOptions{Profile: "core"})
+
+	for _, f := range []string{"sub/prepared", "built"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected %s to exist: %v", f, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "published")); err == nil {
+		t.Error("expected step after a hard failure to be skipped")
+	}
+	if !paused {
+		t.Error("expected a pause after a failed pipeline")
+	}
+
+	got, err := ReadHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatalf("ReadHistory failed: %v", err)
+	}
+	var steps []string
+	for i := len(got) - 1; i >= 0; i-- {
+		if got[i].Cell != "Deploy" {
+			t.Fatalf("unexpected cell in entry: %+v", got[i])
+		}
+		steps = append(steps, got[i].Step)
+	}
+	if strings.Join(steps, ",") != "prepare,lint,step 3,test" {
+		t.Fatalf("unexpected recorded steps: %v", steps)
+	}
+}
+
+func TestRunCommand_StepsCannotRunInBackground(t *testing.T) {
+	cfg := config.Config{Commands: []config.Command{{Name: "Deploy", Steps: []config.CommandStep{{Command: "true"}}}}}
+	if _, _, err := prepareCommand(cfg, "Deploy", RunOptions{}); !errors.Is(err, errStepsNeedForeground) {
+		t.Fatalf("expected errStepsNeedForeground, got %v", err)
+	}
+}
+
+func TestFormatStepSummary(t *testing.T) {
+	out := FormatStepSummary("Deploy", []StepResult{
+		{Name: "build", Status: StepOK, Duration: 1200 * time.Millisecond},
+		{Name: "test", Status: StepFailed, Duration: time.Second, Err: errors.New("exit status 1")},
+		{Name: "publish", Status: StepSkipped},
+	})
+	for _, want := range []string{"Summary: Deploy", "✔ 1. build    ok       1.2s", "✘ 2. test     failed   1s  (exit status 1)", "- 3. publish  skipped"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
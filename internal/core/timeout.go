@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// timeoutGrace is how long a timed-out command gets to exit after each signal
+// before drako escalates to the next one.
+var timeoutGrace = 2 * time.Second
+
+var errTimedOut = errors.New("timed out")
+
+// CommandTimeout returns how long a command may run: its own timeout, or the
+// profile default. Zero means no limit.
+func CommandTimeout(cfg config.Config, spec CommandSpec) (time.Duration, error) {
+	raw := strings.TrimSpace(spec.Timeout)
+	if raw == "" {
+		raw = strings.TrimSpace(cfg.Timeout)
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q (use e.g. \"30s\" or \"5m\")", raw)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must not be negative", raw)
+	}
+	return d, nil
+}
+
+// runWithTimeout runs cmd to completion. With a timeout it runs in its own process group
+// (in the terminal's foreground when foreground is set, so Ctrl+C only reaches the command),
+// and once the deadline passes the group gets SIGINT, then SIGTERM, then SIGKILL.
+// The returned error wraps errTimedOut if the deadline was hit.
+func runWithTimeout(cmd *exec.Cmd, timeout time.Duration, foreground bool) error {
+	if timeout <= 0 {
+		return cmd.Run()
+	}
+
+	restore := setProcessGroup(cmd, foreground)
+	defer restore()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	return stopTimedOut(cmd.Process, done, timeout)
+}
+
+// stopTimedOut stops the process group of a command that ran past its timeout: SIGINT, then
+// SIGTERM, then SIGKILL, each after timeoutGrace. done delivers the command's Wait result.
+// The returned error wraps errTimedOut.
+func stopTimedOut(proc *os.Process, done <-chan error, timeout time.Duration) error {
+	timedOut := fmt.Errorf("%w after %s", errTimedOut, timeout)
+	for _, signal := range []func(*os.Process) error{interruptProcessGroup, terminateProcessGroup, killProcessGroup} {
+		if err := signal(proc); err != nil {
+			// The group is already gone; Wait will return shortly.
+			break
+		}
+		select {
+		case <-done:
+			return timedOut
+		case <-time.After(timeoutGrace):
+		}
+	}
+	<-done
+	return timedOut
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	profile := config.Config{Timeout: "1m"}
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		spec    CommandSpec
+		want    time.Duration
+		wantErr bool
+	}{
+		{"no limit", config.Config{}, CommandSpec{}, 0, false},
+		{"profile default", profile, CommandSpec{}, time.Minute, false},
+		{"command wins", profile, CommandSpec{Timeout: "5s"}, 5 * time.Second, false},
+		{"zero disables", profile, CommandSpec{Timeout: "0"}, 0, false},
+		{"invalid", config.Config{}, CommandSpec{Timeout: "soon"}, 0, true},
+		{"negative", config.Config{}, CommandSpec{Timeout: "-1s"}, 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CommandTimeout(tc.cfg, tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveCommandSpec_ItemInheritsTimeout(t *testing.T) {
+	parent := &config.Command{Name: "SSH", Timeout: "10s", Items: []config.CommandItem{{Name: "web"}, {Name: "db", Timeout: "1m"}}}
+	if got := ResolveCommandSpec(parent, &parent.Items[0]).Timeout; got != "10s" {
+		t.Fatalf("expected item to inherit timeout, got %q", got)
+	}
+	if got := ResolveCommandSpec(parent, &parent.Items[1]).Timeout; got != "1m" {
+		t.Fatalf("expected item timeout to win, got %q", got)
+	}
+}
+
+func TestRunWithTimeout_EscalatesSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and POSIX signals")
+	}
+	oldGrace := timeoutGrace
+	defer func() { timeoutGrace = oldGrace }()
+	timeoutGrace = 100 * time.Millisecond
+
+	// Fast path: a command that finishes in time is untouched.
+	if err := runWithTimeout(exec.Command("sh", "-c", "exit 0"), time.Second, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// SIGINT is ignored, so only the SIGTERM step stops it.
+	start := time.Now()
+	err := runWithTimeout(exec.Command("sh", "-c", "trap '' INT; sleep 10"), 100*time.Millisecond, false)
+	if !errors.Is(err, errTimedOut) || !strings.Contains(err.Error(), "after 100ms") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("escalation took too long: %v", time.Since(start))
+	}
+}
+
+func TestRunCommand_TimeoutIsRecorded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) {}
+
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Timeout:      "100ms",
+		Commands:     []config.Command{{Name: "hang", Command: "sleep 10"}},
+	}
+	RunCommandWith(cfg, "hang", RunOptions{})
+
+	got, err := ReadHistory(HistoryFilter{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected one history entry, got %v (%v)", got, err)
+	}
+	if got[0].Succeeded() || !strings.Contains(got[0].Error, "timed out") {
+		t.Fatalf("expected timed out entry, got %+v", got[0])
+	}
+}
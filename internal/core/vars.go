@@ -0,0 +1,62 @@
+package core
+
+import (
+	"os"
+	"strings"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// Built-in variables, available in every command as {{name}} and exported as DRAKO_<NAME>.
+const (
+	VarAssetsDir = config.VarAssetsDir // ~/.config/drako/assets/<profile>
+	VarConfigDir = config.VarConfigDir // ~/.config/drako
+	VarProfile   = config.VarProfile   // name of the active profile
+	VarCwd       = config.VarCwd       // directory drako is in (path mode)
+	VarSelection = config.VarSelection // sub-directory picked in path mode, if any
+)
+
+var builtinVarNames = config.BuiltinVarNames
+
+// IsBuiltinVar reports whether name is reserved for a built-in variable.
+func IsBuiltinVar(name string) bool {
+	for _, v := range builtinVarNames {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// BuiltinVars returns the values of the built-in variables for a run.
+// Values that cannot be determined (e.g. the assets dir without a profile) are empty.
+func BuiltinVars(opts RunOptions) map[string]string {
+	vars := make(map[string]string, len(builtinVarNames))
+	for _, name := range builtinVarNames {
+		vars[name] = ""
+	}
+
+	if dir, err := config.GetConfigDir(); err == nil {
+		vars[VarConfigDir] = dir
+	}
+	if strings.TrimSpace(opts.Profile) != "" {
+		vars[VarProfile] = opts.Profile
+		if dir, err := AssetsDir(opts.Profile); err == nil {
+			vars[VarAssetsDir] = dir
+		}
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		vars[VarCwd] = cwd
+	}
+	vars[VarSelection] = opts.Selection
+	return vars
+}
+
+// BuiltinEnv returns the built-in variables under their environment names (DRAKO_ASSETS_DIR, ...).
+func BuiltinEnv(vars map[string]string) map[string]string {
+	env := make(map[string]string, len(vars))
+	for name, v := range vars {
+		env["DRAKO_"+strings.ToUpper(name)] = v
+	}
+	return env
+}
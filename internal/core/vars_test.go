@@ -0,0 +1,71 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestBuiltinVars(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfgDir, err := config.GetConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+
+	vars := BuiltinVars(RunOptions{Profile: "git", Selection: "/src/repo"})
+	want := map[string]string{
+		VarAssetsDir: filepath.Join(cfgDir, "assets", "git"),
+		VarConfigDir: cfgDir,
+		VarProfile:   "git",
+		VarCwd:       cwd,
+		VarSelection: "/src/repo",
+	}
+	for name, v := range want {
+		if vars[name] != v {
+			t.Errorf("%s: got %q, want %q", name, vars[name], v)
+		}
+	}
+
+	if env := BuiltinEnv(vars); env["DRAKO_ASSETS_DIR"] != want[VarAssetsDir] || env["DRAKO_SELECTION"] != "/src/repo" {
+		t.Fatalf("unexpected env names: %v", env)
+	}
+
+	if vars := BuiltinVars(RunOptions{}); vars[VarAssetsDir] != "" || vars[VarProfile] != "" {
+		t.Fatalf("expected empty profile vars without a profile, got %v", vars)
+	}
+}
+
+func TestRunCommand_ExpandsAndExportsBuiltins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) {}
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{{
+			Name:    "Show",
+			Command: `echo {{profile}} {{selection}} "$DRAKO_PROFILE" "$DRAKO_SELECTION" > ` + out,
+		}},
+	}
+	RunCommandWith(cfg, "Show", RunOptions{Profile: "ops", Selection: "my dir"})
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("expected command to run: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != "ops my dir ops my dir" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+// assetsPrefix marks a path as relative to the active profile's assets directory.
+const assetsPrefix = "@assets"
+
+// AssetsDir returns the directory summon copies a profile's assets into.
+func AssetsDir(profile string) (string, error) {
+	cfgDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfgDir, "assets", profile), nil
+}
+
+// ResolveCommandPath turns a path from a command definition into an absolute path.
+// Accepted forms: absolute paths, ~ and ~/..., @assets and @assets/... (relative to the
+// profile's assets directory) and anything else relative to base (the path-mode directory).
+func ResolveCommandPath(p, base, profile string) (string, error) {
+	p = strings.TrimSpace(p)
+	switch {
+	case p == "":
+		return base, nil
+	case p == "~" || strings.HasPrefix(p, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, strings.TrimPrefix(p, "~")), nil
+	case p == assetsPrefix || strings.HasPrefix(p, assetsPrefix+"/"):
+		if strings.TrimSpace(profile) == "" {
+			return "", fmt.Errorf("%s needs an active profile", assetsPrefix)
+		}
+		dir, err := AssetsDir(profile)
+		if err != nil {
+			return "", err
+		}
+		rel := filepath.Clean("/" + strings.TrimPrefix(p, assetsPrefix))
+		return filepath.Join(dir, rel), nil
+	case filepath.IsAbs(p):
+		return filepath.Clean(p), nil
+	default:
+		return filepath.Join(base, p), nil
+	}
+}
+
+// ResolveWorkDir returns the directory a command runs in and checks that it exists.
+// An empty cwd means base, i.e. wherever path mode left drako.
+func ResolveWorkDir(cwd, base, profile string) (string, error) {
+	dir, err := ResolveCommandPath(cwd, base, profile)
+	if err != nil {
+		return "", fmt.Errorf("cwd %q: %w", cwd, err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("cwd %s does not exist", dir)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("cwd %s is not a directory", dir)
+	}
+	return dir, nil
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lucky7xz/drako/internal/config"
+)
+
+func TestResolveCommandPath(t *testing.T) {
+	cfgHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfgHome)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	cfgDir, err := config.GetConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets := filepath.Join(cfgDir, "assets", "core")
+	base := filepath.Join(string(filepath.Separator), "work", "repo")
+	abs := filepath.Join(string(filepath.Separator), "srv", "app")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", base},
+		{"build", filepath.Join(base, "build")},
+		{"../other", filepath.Join(string(filepath.Separator), "work", "other")},
+		{abs, abs},
+		{"~", home},
+		{"~/src", filepath.Join(home, "src")},
+		{"@assets", assets},
+		{"@assets/scripts", filepath.Join(assets, "scripts")},
+		{"@assets/../../escape", filepath.Join(assets, "escape")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ResolveCommandPath(tc.in, base, "core")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := ResolveCommandPath("@assets/x", base, ""); err == nil {
+		t.Fatal("expected error for @assets without a profile")
+	}
+}
+
+func TestResolveWorkDir_RequiresDirectory(t *testing.T) {
+	base := t.TempDir()
+	if err := os.WriteFile(filepath.Join(base, "file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveWorkDir("missing", base, "core"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected missing dir error, got %v", err)
+	}
+	if _, err := ResolveWorkDir("file", base, "core"); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Fatalf("expected not a directory error, got %v", err)
+	}
+}
+
+func TestRunCommand_AppliesCwdAndEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	oldPause := pauseFn
+	defer func() { pauseFn = oldPause }()
+	pauseFn = func(string) {}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "vars.env"), []byte("FROM_FILE=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{
+		DefaultShell: "sh",
+		Commands: []config.Command{{
+			Name: "Tools",
+			Cwd:  dir,
+			Env:  map[string]string{"SHARED": "parent"},
+			Items: []config.CommandItem{{
+				Name:    "Write",
+				Command: `echo "$SHARED $OWN $FROM_FILE" > out.txt`,
+				EnvFile: "vars.env",
+				Env:     map[string]string{"OWN": "item"},
+			}},
+		}},
+	}
+	RunCommandWith(cfg, "Write", RunOptions{Profile: "core"})
+
+	got, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("expected command to run in cwd: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != "parent item 1" {
+		t.Fatalf("unexpected env in command: %q", got)
+	}
+}
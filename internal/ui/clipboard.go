@@ -48,6 +48,27 @@ func tryPlatformClipboard(s string) bool {
 	return false
 }
 
+// ClipboardBackend names the tool CopyToClipboard uses on this system, or returns "" when only
+// the OSC52 terminal fallback is left.
+func ClipboardBackend() string {
+	switch runtime.GOOS {
+	case "linux":
+		cmd, _ := getLinuxClipboardCommand()
+		return cmd
+	case "darwin":
+		if _, err := exec.LookPath("pbcopy"); err == nil {
+			return "pbcopy"
+		}
+	case "windows":
+		for _, cmd := range []string{"powershell.exe", "clip.exe"} {
+			if _, err := exec.LookPath(cmd); err == nil {
+				return cmd
+			}
+		}
+	}
+	return ""
+}
+
 // getLinuxClipboardCommand returns the appropriate clipboard command for Linux systems
 func getLinuxClipboardCommand() (string, []string) {
 	// Check for Wayland first
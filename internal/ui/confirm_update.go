@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+// confirmModel holds the state of the confirmation prompt shown before a guarded cell runs.
+type confirmModel struct {
+	target     string // Name of the cell that will run once confirmed
+	command    string // Command line shown for context
+	level      string // core.ConfirmYes or core.ConfirmName
+	input      string // Typed name for core.ConfirmName
+	err        string
+	returnMode navMode // Mode to go back to on cancel
+}
+
+func newConfirmPrompt(spec core.CommandSpec, level string, returnMode navMode) confirmModel {
+	return confirmModel{
+		target:     spec.Name,
+		command:    spec.Command,
+		level:      level,
+		returnMode: returnMode,
+	}
+}
+
+// updateConfirmMode handles key input while the confirmation prompt is open.
+// Anything but an explicit yes (or the exact cell name) cancels, so a stray Enter is harmless.
+func (m Model) updateConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &m.confirm
+
+	if c.level == core.ConfirmName {
+		switch msg.Type {
+		case tea.KeyEsc:
+			return m.cancelConfirm()
+		case tea.KeyEnter:
+			if strings.TrimSpace(c.input) != strings.TrimSpace(c.target) {
+				c.err = "Name does not match"
+				return m, nil
+			}
+			return m.acceptConfirm()
+		case tea.KeyBackspace:
+			if r := []rune(c.input); len(r) > 0 {
+				c.input = string(r[:len(r)-1])
+			}
+		case tea.KeyCtrlU:
+			c.input = ""
+		case tea.KeySpace:
+			c.input += " "
+		case tea.KeyRunes:
+			c.input += string(msg.Runes)
+		}
+		c.err = ""
+		return m, nil
+	}
+
+	if msg.String() == "y" || msg.String() == "Y" {
+		return m.acceptConfirm()
+	}
+	return m.cancelConfirm()
+}
+
+func (m Model) acceptConfirm() (tea.Model, tea.Cmd) {
+	target := m.confirm.target
+	m.mode = m.confirm.returnMode
+	m.confirm = confirmModel{}
+	m.confirmed = true
+	return m.startExecution(target)
+}
+
+// cancelConfirm drops everything collected for the pending execution.
+func (m Model) cancelConfirm() (tea.Model, tea.Cmd) {
+	m.mode = m.confirm.returnMode
+	m.confirm = confirmModel{}
+	m.clearPending()
+	m.Replay = nil
+	return m, m.setProfileStatus("Cancelled", false)
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+func (m Model) viewConfirmMode() string {
+	layout := CalculateLayout(m.termWidth, m.termHeight, m.Config)
+	header := ""
+	if layout.ShowHeader {
+		header = renderHeaderArt(m.spinner.View())
+	}
+
+	c := m.confirm
+	bg := dropdownPopupStyle.GetBackground()
+	bgFill := lipgloss.NewStyle().Background(bg)
+	titleStyleLocal := titleStyle.Background(bg)
+	labelStyle := helpStyle.Background(bg)
+	textNorm := itemStyle.Background(bg)
+	textSel := selectedItemStyle.Background(bg)
+	errStyle := errorTextStyle.Background(bg)
+
+	var raw []string
+	raw = append(raw, titleStyleLocal.Render(fmt.Sprintf("Run %s?", c.target)))
+	raw = append(raw, "")
+	if strings.TrimSpace(c.command) != "" {
+		raw = append(raw, labelStyle.Render("Command: ")+textNorm.Render(truncateRunes(c.command, 60)))
+		raw = append(raw, "")
+	}
+
+	help := "y: Run • any other key: Cancel"
+	if c.level == core.ConfirmName {
+		raw = append(raw, labelStyle.Render("Type the cell name to confirm: ")+textSel.Render(c.input+"_"))
+		help = "Enter: Run • Esc: Cancel"
+	}
+
+	if c.err != "" {
+		raw = append(raw, "")
+		raw = append(raw, errStyle.Render(c.err))
+	}
+
+	raw = append(raw, "")
+	raw = append(raw, helpStyle.Render(help))
+
+	maxW := 0
+	for _, line := range raw {
+		if w := lipgloss.Width(line); w > maxW {
+			maxW = w
+		}
+	}
+
+	var lines []string
+	for _, line := range raw {
+		pad := maxW - lipgloss.Width(line)
+		if pad < 0 {
+			pad = 0
+		}
+		lines = append(lines, line+bgFill.Render(strings.Repeat(" ", pad)))
+	}
+
+	popup := dropdownPopupStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	content := lipgloss.JoinVertical(lipgloss.Center, header, popup)
+	return appStyle.Render(lipgloss.Place(m.termWidth, m.termHeight, lipgloss.Center, lipgloss.Center, content))
+}
@@ -0,0 +1,384 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucky7xz/drako/internal/config"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+// startExecution is the single path from "the user picked a cell" to "the TUI hands it to RunCommand".
+// Cells whose container is not running stop here with a status message. Cells that declare
+// parameters are routed through the prompt overlay first, then cells that require confirmation
+// through the confirm modal; cells with a launcher (tmux) and background cells are started
+// without leaving the TUI. History re-runs (m.Replay) skip the parameter prompt.
+func (m Model) startExecution(name string) (tea.Model, tea.Cmd) {
+	// A history re-run is checked against, and runs with, the profile it was recorded in.
+	cfg := m.Config
+	if m.Replay != nil {
+		var ok bool
+		if cfg, ok = m.profileConfig(m.Replay.Profile); !ok {
+			msg := fmt.Sprintf("%s ran in profile %s, which is no longer equipped", name, m.Replay.Profile)
+			m.clearPending()
+			m.mode = gridMode
+			return m, m.setProfileStatus(msg, false)
+		}
+	}
+	spec := core.CommandSpec{Name: name}
+	if parent, item, found := core.FindCommandIn(cfg, m.Parent, name); found {
+		spec = core.ResolveCommandSpec(parent, item)
+		// Re-runs from history or the jobs panel bypass the grid's own check.
+		unmet := parent.Unmet
+		if unmet == "" && item != nil {
+			unmet = item.Unmet
+		}
+		if unmet != "" {
+			m.clearPending()
+			m.mode = gridMode
+			return m, tea.Batch(append(m.dueRequirementChecks(cfg), m.setProfileStatus("Unavailable: "+unmet, false))...)
+		}
+	} else if m.Replay != nil {
+		// The cell is gone, so its confirm setting is unknown; ask before running the recorded command.
+		spec.Confirm = core.ConfirmYes
+	}
+
+	// Say so up front when the container is down, rather than failing inside the shell. The
+	// runtime is asked off the update loop; execution picks up again on containerCheckedMsg.
+	if target := core.EffectiveContainer(m.Config, spec); target != nil && m.Replay == nil && m.containerChecked != name {
+		m.checkingContainer = name
+		target, mode := *target, m.mode
+		return m, func() tea.Msg {
+			return containerCheckedMsg{name: name, mode: mode, err: core.CheckContainer(target)}
+		}
+	}
+
+	// A profile with several hosts asks for the target before every run.
+	var hosts []string
+	if len(m.Config.Hosts) > 1 && m.Host == "" {
+		hosts = m.Config.Hosts
+	}
+	if m.Replay == nil && ((len(spec.Params) > 0 && m.Params == nil) || len(hosts) > 0) {
+		m.paramForm = newParamForm(spec, hosts, m.mode)
+		m.mode = paramMode
+		return m, nil
+	}
+
+	// Nothing runs in a dry run, so there is nothing to confirm either.
+	if m.DryRun {
+		return m.showDryRun(name)
+	}
+
+	if level := m.confirmLevel(spec); level != core.ConfirmNone && !m.confirmed {
+		m.confirm = newConfirmPrompt(spec, level, m.mode)
+		m.mode = confirmMode
+		return m, nil
+	}
+
+	if m.Replay == nil && len(spec.Steps) == 0 {
+		launcher, err := core.EffectiveLauncher(m.Config, spec)
+		if err != nil {
+			m.clearPending()
+			m.mode = gridMode
+			return m, m.setProfileStatus(err.Error(), false)
+		}
+		// Outside tmux (or another multiplexer) the command simply runs inline.
+		if launcher != core.LauncherInline && core.LauncherAvailable(launcher) {
+			err := core.Launch(m.Config, name, launcher, m.RunOptions())
+			m.clearPending()
+			m.mode = gridMode
+			if err != nil {
+				return m, m.setProfileStatus(fmt.Sprintf("Launch failed: %v", err), false)
+			}
+			return m, m.setProfileStatus(fmt.Sprintf("Opened %s in %s", name, launcher), true)
+		}
+	}
+
+	if m.Replay == nil && spec.Background != nil && *spec.Background {
+		job, err := core.StartJob(m.Config, name, m.RunOptions())
+		m.clearPending()
+		m.mode = gridMode
+		if err != nil {
+			return m, m.setProfileStatus(fmt.Sprintf("Job failed to start: %v", err), false)
+		}
+		return m, m.setProfileStatus(fmt.Sprintf("Job #%d started: %s", job.ID, job.Name), true)
+	}
+
+	m.Selected = name
+	return m, tea.Quit
+}
+
+// showDryRun resolves the cell like RunCommand would and shows the result in the detail overlay.
+func (m Model) showDryRun(name string) (tea.Model, tea.Cmd) {
+	report, err := core.DryRun(m.Config, name, m.RunOptions())
+	m.clearPending()
+	m.Replay = nil
+	m.previousMode = m.mode
+	m.mode = infoMode
+
+	detail := &DetailState{Title: "Dry run: " + name, KeyLabel: "Exec"}
+	m.activeDetail = detail
+	if err != nil {
+		detail.Value = "Error: " + err.Error()
+		return m, nil
+	}
+
+	var execLines []string
+	for i, p := range report.Processes {
+		line := core.FormatArgv(p.Argv)
+		if p.Step != "" {
+			line = fmt.Sprintf("%d. %s: %s", i+1, p.Step, line)
+		}
+		execLines = append(execLines, line)
+	}
+	detail.Value = strings.Join(execLines, "\n")
+	detail.Description = "Nothing was executed."
+
+	if len(report.Processes) > 0 {
+		detail.Meta = append(detail.Meta, DetailMeta{Label: "CWD", Value: report.Processes[0].Dir})
+	}
+	detail.Meta = append(detail.Meta, DetailMeta{Label: "Launcher", Value: report.Launcher})
+	if report.Timeout > 0 {
+		detail.Meta = append(detail.Meta, DetailMeta{Label: "Timeout", Value: report.Timeout.String()})
+	}
+	detail.Meta = append(detail.Meta, DetailMeta{Label: "Env", Value: fmt.Sprintf("%d variables, %d set by drako, %d filtered out", len(report.Env), len(report.Set), len(report.Removed))})
+	if len(report.Set) > 0 {
+		keys := make([]string, 0, len(report.Set))
+		for k := range report.Set {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+report.Set[k])
+		}
+		detail.Meta = append(detail.Meta, DetailMeta{Label: "Set", Value: strings.Join(pairs, " ")})
+	}
+	if len(report.Removed) > 0 {
+		detail.Meta = append(detail.Meta, DetailMeta{Label: "Filtered", Value: strings.Join(report.Removed, " ")})
+	}
+	return m, nil
+}
+
+// clearPending drops the parameters, host and confirmation collected for an execution
+// that has been started (or abandoned) without leaving the TUI.
+func (m *Model) clearPending() {
+	m.Replay = nil
+	m.Params = nil
+	m.Host = ""
+	m.Parent = ""
+	m.confirmed = false
+	m.checkingContainer = ""
+	m.containerChecked = ""
+}
+
+// profileConfig returns the effective config of the named profile, with requirements applied.
+// The active profile gives m.Config; false means the profile is no longer equipped.
+func (m Model) profileConfig(name string) (config.Config, bool) {
+	if name == "" || name == m.activeProfileName() {
+		return m.Config, true
+	}
+	for _, p := range m.profiles {
+		if p.Name == name {
+			cfg := config.ApplyProfileOverlay(m.baseConfig, p.Profile)
+			cfg.Commands = core.ApplyRequirements(cfg)
+			return cfg, true
+		}
+	}
+	return config.Config{}, false
+}
+
+// RunConfig returns the config the selected command runs with: that of the recorded profile
+// for a history re-run, m.Config otherwise.
+func (m Model) RunConfig() config.Config {
+	if m.Replay != nil {
+		if cfg, ok := m.profileConfig(m.Replay.Profile); ok {
+			return cfg
+		}
+	}
+	return m.Config
+}
+
+// confirmLevel is the confirmation a cell needs; glassroot mode can raise it for every cell.
+func (m Model) confirmLevel(spec core.CommandSpec) string {
+	minimum := core.ConfirmNone
+	if m.GlassrootMode {
+		minimum = m.Config.GlassrootConfirm
+	}
+	return core.EffectiveConfirm(spec, minimum)
+}
+
+// RunOptions returns the runtime context collected in the TUI for the selected command.
+func (m Model) RunOptions() core.RunOptions {
+	opts := core.RunOptions{
+		Params:    m.Params,
+		Profile:   m.activeProfileName(),
+		Replay:    m.Replay,
+		Selection: m.path.Selection,
+		Host:      m.Host,
+		Parent:    m.Parent,
+		DryRun:    m.DryRun,
+	}
+	if m.Replay != nil && m.Replay.Profile != "" {
+		opts.Profile = m.Replay.Profile
+	}
+	return opts
+}
+
+// withUnmet puts the reason a cell is unavailable at the top of its explain metadata.
+func withUnmet(unmet string, meta []DetailMeta) []DetailMeta {
+	if unmet == "" {
+		return meta
+	}
+	return append([]DetailMeta{{Label: "Unavailable", Value: unmet}}, meta...)
+}
+
+// containerState looks up the state of spec's container for the explain overlay titled title,
+// off the update loop. It returns nil for cells that do not run in a container.
+func (m Model) containerState(title string, spec core.CommandSpec) tea.Cmd {
+	target := core.EffectiveContainer(m.Config, spec)
+	if target == nil {
+		return nil
+	}
+	t := *target
+	return func() tea.Msg {
+		value := containerLabel(t)
+		if err := core.CheckContainer(t); err != nil {
+			value += " (Error: " + err.Error() + ")"
+		}
+		return containerStateMsg{title: title, value: value}
+	}
+}
+
+// containerLabel names a container and the user commands run as.
+func containerLabel(t config.ContainerTarget) string {
+	if t.User != "" {
+		return t.Name + " as " + t.User
+	}
+	return t.Name
+}
+
+// executionMeta describes how a cell would run, for the explain overlay. The container state is
+// filled in later by containerState.
+// CWD and env are resolved the same way RunCommand resolves them, so errors show up before running.
+func (m Model) executionMeta(spec core.CommandSpec) []DetailMeta {
+	execMode := "live"
+	if spec.DebugExecution != nil && *spec.DebugExecution {
+		execMode = "debug"
+	}
+	if spec.Background != nil && *spec.Background {
+		execMode = "background"
+	}
+	autoClose := spec.AutoCloseExecution == nil || *spec.AutoCloseExecution
+
+	profile := m.activeProfileName()
+	var cwd, cwdLabel string
+	if target := core.EffectiveContainer(m.Config, spec); target != nil {
+		// Container cwds are paths inside the container; env_file is still read locally.
+		cwd = m.path.CurrentPath
+		cwdLabel = spec.Cwd
+		if cwdLabel == "" {
+			cwdLabel = target.Workdir
+		}
+		if cwdLabel == "" {
+			cwdLabel = "(container default)"
+		}
+	} else if len(m.Config.Hosts) > 0 {
+		// Remote cwds are paths on the host; env_file is still read locally.
+		cwd = m.path.CurrentPath
+		cwdLabel = spec.Cwd
+		if cwdLabel == "" {
+			cwdLabel = "~ (remote login directory)"
+		}
+	} else {
+		var err error
+		cwd, err = core.ResolveWorkDir(spec.Cwd, m.path.CurrentPath, profile)
+		cwdLabel = cwd
+		if err != nil {
+			cwd = m.path.CurrentPath
+			cwdLabel = "Error: " + err.Error()
+		}
+	}
+	meta := []DetailMeta{
+		{Label: "Exec", Value: execMode},
+		{Label: "Auto-close", Value: fmt.Sprintf("%v", autoClose)},
+		{Label: "CWD", Value: cwdLabel},
+	}
+	if len(m.Config.Hosts) > 0 {
+		meta = append(meta, DetailMeta{Label: "Host", Value: strings.Join(m.Config.Hosts, ", ")})
+	}
+	if target := core.EffectiveContainer(m.Config, spec); target != nil {
+		meta = append(meta, DetailMeta{Label: "Container", Value: containerLabel(*target) + " (checking…)"})
+	}
+
+	if launcher, err := core.EffectiveLauncher(m.Config, spec); err != nil {
+		meta = append(meta, DetailMeta{Label: "Launcher", Value: "Error: " + err.Error()})
+	} else if launcher != core.LauncherInline {
+		if !core.LauncherAvailable(launcher) {
+			launcher += " (unavailable, runs inline)"
+		}
+		meta = append(meta, DetailMeta{Label: "Launcher", Value: launcher})
+	}
+	if level := m.confirmLevel(spec); level != core.ConfirmNone {
+		meta = append(meta, DetailMeta{Label: "Confirm", Value: level})
+	}
+	if timeout, err := core.CommandTimeout(m.Config, spec); err != nil {
+		meta = append(meta, DetailMeta{Label: "Timeout", Value: "Error: " + err.Error()})
+	} else if timeout > 0 {
+		meta = append(meta, DetailMeta{Label: "Timeout", Value: timeout.String()})
+	}
+	switch {
+	case spec.Notify != nil && *spec.Notify:
+		meta = append(meta, DetailMeta{Label: "Notify", Value: "when done"})
+	case spec.Notify == nil && strings.TrimSpace(m.Config.NotifyAfter) != "":
+		meta = append(meta, DetailMeta{Label: "Notify", Value: "after " + strings.TrimSpace(m.Config.NotifyAfter)})
+	}
+	if e, ok := m.lastRuns[spec.Name]; ok {
+		outcome := "succeeded"
+		if !e.Succeeded() {
+			outcome = fmt.Sprintf("failed (exit %d)", e.ExitCode)
+		}
+		meta = append(meta, DetailMeta{Label: "Last run", Value: fmt.Sprintf("%s %s, took %s", outcome, e.Start.Format("2006-01-02 15:04"), e.Duration().Round(time.Millisecond))})
+	}
+	if e, ok := core.LastCapture(profile, spec.Name); ok {
+		meta = append(meta, DetailMeta{Label: "Last output", Value: fmt.Sprintf("%s (%s)", e.Output, e.Start.Format("2006-01-02 15:04"))})
+	}
+	if spec.EnvFile != "" {
+		meta = append(meta, DetailMeta{Label: "Env file", Value: spec.EnvFile})
+	}
+	overrides, err := core.CommandEnvOverrides(spec, cwd, profile)
+	if err != nil {
+		meta = append(meta, DetailMeta{Label: "Env", Value: "Error: " + err.Error()})
+	} else if len(overrides) > 0 {
+		keys := make([]string, 0, len(overrides))
+		for k := range overrides {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+overrides[k])
+		}
+		meta = append(meta, DetailMeta{Label: "Env", Value: strings.Join(pairs, " ")})
+	}
+	return meta
+}
+
+// stepsPreview lists the steps of a multi-step command, one per line, for the explain overlay.
+func stepsPreview(steps []config.CommandStep) string {
+	lines := make([]string, 0, len(steps))
+	for i, step := range steps {
+		line := fmt.Sprintf("%d. %s: %s", i+1, core.StepName(step, i), step.Command)
+		if step.Cwd != "" {
+			line += fmt.Sprintf(" (in %s)", step.Cwd)
+		}
+		if step.ContinueOnError {
+			line += " [continue on error]"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,7 +1,6 @@
 package ui
 
 import (
-	"fmt"
 	"math"
 	"strconv"
 	"strings"
@@ -73,6 +72,26 @@ func (m Model) updateGridMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = pathMode
 	// ====================
 
+	case IsJobs(m.Config.Keys, msg):
+		return m.openJobsPanel()
+
+	case IsHistory(m.Config.Keys, msg):
+		return m.openHistoryBrowser()
+
+	case IsDryRun(m.Config.Keys, msg):
+		m.DryRun = !m.DryRun
+		if m.DryRun {
+			return m, m.setProfileStatus("Dry run on: cells show what would run", true)
+		}
+		return m, m.setProfileStatus("Dry run off", true)
+
+	case IsOutput(m.Config.Keys, msg):
+		e, ok := core.LastCapture("", "")
+		if !ok {
+			return m, m.setProfileStatus("No captured output yet (run a debug command)", false)
+		}
+		return m.OpenOutput(e), nil
+
 	case IsUp(m.Config.Keys, msg):
 		m.moveCursor(-1, 0)
 	case IsDown(m.Config.Keys, msg):
@@ -90,40 +109,27 @@ func (m Model) updateGridMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if cmd.Name == selectedChoice {
 				m.previousMode = m.mode
 
-				// Resolve execution mode and auto-close
-				autoClose := true
-				if cmd.AutoCloseExecution != nil {
-					autoClose = *cmd.AutoCloseExecution
-				}
-				debug := false
-				if cmd.DebugExecution != nil {
-					debug = *cmd.DebugExecution
-				}
-				execMode := "live"
-				if debug {
-					execMode = "debug"
-				}
-
-				cmdStr := ""
-				if strings.TrimSpace(cmd.Command) == "" {
+				cmdStr, keyLabel := "", "Command"
+				if len(cmd.Steps) > 0 {
+					cmdStr, keyLabel = stepsPreview(cmd.Steps), "Steps"
+				} else if strings.TrimSpace(cmd.Command) == "" && strings.TrimSpace(cmd.ItemsCommand) != "" {
+					cmdStr, keyLabel = cmd.ItemsCommand, "Items from"
+				} else if strings.TrimSpace(cmd.Command) == "" {
 					cmdStr = "Error: no command. ( This might be a folder of commands!)"
 				} else {
 					cmdStr = cmd.Command
 				}
 
+				spec := core.ResolveCommandSpec(&cmd, nil)
 				m.activeDetail = &DetailState{
 					Title:       selectedChoice,
-					KeyLabel:    "Command",
+					KeyLabel:    keyLabel,
 					Value:       cmdStr,
 					Description: cmd.Description,
-					Meta: []DetailMeta{
-						{Label: "Exec", Value: execMode},
-						{Label: "Auto-close", Value: fmt.Sprintf("%v", autoClose)},
-						{Label: "CWD", Value: m.path.CurrentPath},
-					},
+					Meta:        withUnmet(cmd.Unmet, m.executionMeta(spec)),
 				}
 				m.mode = infoMode
-				return m, nil
+				return m, m.containerState(selectedChoice, spec)
 			}
 		}
 		// Not found in config
@@ -156,21 +162,18 @@ func (m Model) updateGridMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Check if this command has dropdown items
 			for _, cmd := range m.Config.Commands {
 				if cmd.Name == selectedChoice {
-					if len(cmd.Items) > 0 {
-						// Open dropdown menu
-						m.mode = dropdownMode
-						m.dropdownRow = m.cursorRow
-						m.dropdownCol = m.cursorCol
-						m.dropdownItems = cmd.Items
-						m.dropdownSelectedIdx = 0
-						return m, nil
+					if cmd.Unmet != "" {
+						return m, m.setProfileStatus("Unavailable: "+cmd.Unmet, false)
+					}
+					if len(cmd.Items) > 0 || strings.TrimSpace(cmd.ItemsCommand) != "" {
+						return m.openDropdown(cmd)
 					}
 					break
 				}
 			}
 			// Single command, execute normally
-			m.Selected = selectedChoice
-			return m, tea.Quit
+			m.Parent = ""
+			return m.startExecution(selectedChoice)
 		}
 	}
 	return m, nil
@@ -5,16 +5,34 @@ import (
 	"os"
 	"os/user"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lucky7xz/drako/internal/config"
+	"github.com/lucky7xz/drako/internal/core"
 )
 
 func (m Model) renderGrid() string {
+	// Cells with a status_command get a second line; then every cell does, so rows stay aligned.
+	statuses := make(map[string]cellStatus)
+	unavailable := make(map[string]bool)
+	for _, cmd := range m.Config.Commands {
+		unavailable[cmd.Name] = cmd.Unmet != ""
+		if strings.TrimSpace(cmd.StatusCommand) != "" {
+			statuses[cmd.Name] = m.cellStatus(cmd)
+		}
+	}
+
 	maxContentWidth := 0
 	for _, row := range m.grid {
 		for _, cell := range row {
 			contentWidth := lipgloss.Width(cell)
+			if st, ok := statuses[cell]; ok && lipgloss.Width(st.text) > contentWidth {
+				contentWidth = lipgloss.Width(st.text)
+			}
 			if contentWidth > maxContentWidth {
 				maxContentWidth = contentWidth
 			}
@@ -55,14 +73,28 @@ func (m Model) renderGrid() string {
 	for r, row := range m.grid {
 		var renderedCells []string
 		for c, cell := range row {
+			run, ran := m.cellLastRun(cell)
 			var style lipgloss.Style
-			if m.mode == gridMode && r == m.cursorRow && c == m.cursorCol {
-				style = selectedCellStyle
-			} else {
+			switch {
+			case m.mode == gridMode && r == m.cursorRow && c == m.cursorCol:
+				style = selectedCellStyle.Faint(unavailable[cell])
+			case unavailable[cell]:
+				style = cellUnavailableStyle
+			case ran && run.Succeeded():
+				style = cellSucceededStyle
+			case ran:
+				style = cellFailedStyle
+			default:
 				style = cellStyle
 			}
 
 			truncatedContent := truncateText(cell, maxContentWidth)
+			if len(statuses) > 0 {
+				truncatedContent += "\n"
+				if st, ok := statuses[cell]; ok {
+					truncatedContent += st.style.Render(truncateText(st.text, maxContentWidth))
+				}
+			}
 
 			// The cell style itself has padding, so we just need to render the content.
 			paddedContent := lipgloss.NewStyle().
@@ -71,6 +103,9 @@ func (m Model) renderGrid() string {
 				Render(truncatedContent)
 
 			renderedCell := style.Render(paddedContent)
+			if ran {
+				renderedCell = withRunBadge(renderedCell, style, run)
+			}
 			renderedCells = append(renderedCells, renderedCell)
 		}
 		renderedRows = append(renderedRows, lipgloss.JoinHorizontal(lipgloss.Top, renderedCells...))
@@ -104,6 +139,104 @@ func (m Model) renderGrid() string {
 	return lipgloss.JoinVertical(lipgloss.Left, paddedHeader, gridBody)
 }
 
+// cellStatus is the line a status_command probe puts on its cell.
+type cellStatus struct {
+	text  string
+	style lipgloss.Style
+}
+
+// cellStatus renders the latest probe of a cell: its first output line in the success or
+// error color, or the exit code when it printed nothing.
+func (m Model) cellStatus(cmd config.Command) cellStatus {
+	r, ok := core.LastProbe(m.activeProfileName(), cmd)
+	positive := lipgloss.NewStyle().Foreground(statusPositiveStyle.GetForeground())
+	negative := lipgloss.NewStyle().Foreground(statusNegativeStyle.GetForeground())
+	switch {
+	case !ok:
+		return cellStatus{text: "…", style: helpStyle}
+	case r.Err != "":
+		return cellStatus{text: "✘ " + r.Err, style: negative}
+	case r.OK():
+		text := ansi.Strip(r.Line)
+		if text == "" {
+			text = "ok"
+		}
+		return cellStatus{text: "● " + text, style: positive}
+	default:
+		text := ansi.Strip(r.Line)
+		if text == "" {
+			text = fmt.Sprintf("exit %d", r.ExitCode)
+		}
+		return cellStatus{text: "● " + text, style: negative}
+	}
+}
+
+// cellLastRun returns the newest recorded run of a grid cell. A dropdown cell reflects
+// whichever of its items ran last.
+func (m Model) cellLastRun(name string) (core.HistoryEntry, bool) {
+	last, ok := m.lastRuns[name]
+	for _, cmd := range m.Config.Commands {
+		if cmd.Name != name {
+			continue
+		}
+		for _, item := range slices.Concat(cmd.Items, cmd.Generated) {
+			if e, found := m.lastRuns[item.Name]; found && (!ok || e.Start.After(last.Start)) {
+				last, ok = e, true
+			}
+		}
+		break
+	}
+	return last, ok
+}
+
+// withRunBadge writes the outcome and age of the last run into the bottom border of a
+// rendered cell, e.g. "┕━━━ ✘ 5m ┙".
+func withRunBadge(rendered string, style lipgloss.Style, run core.HistoryEntry) string {
+	if !style.GetBorderBottom() {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	bottom := lines[len(lines)-1]
+	inner := lipgloss.Width(bottom) - 2
+
+	symbol, badgeStyle := "✔", lipgloss.NewStyle().Foreground(cellSucceededStyle.GetBorderBottomForeground())
+	if !run.Succeeded() {
+		symbol, badgeStyle = "✘", lipgloss.NewStyle().Foreground(cellFailedStyle.GetBorderBottomForeground())
+	}
+	at := run.End
+	if at.IsZero() {
+		at = run.Start
+	}
+	badge := symbol + " " + runAge(time.Since(at))
+	if lipgloss.Width(badge)+2 > inner {
+		badge = symbol
+	}
+	fill := inner - lipgloss.Width(badge) - 2
+	if fill < 0 {
+		return rendered
+	}
+
+	border := lipgloss.NewStyle().Foreground(style.GetBorderBottomForeground())
+	lines[len(lines)-1] = border.Render("┕"+strings.Repeat("━", fill)+" ") +
+		badgeStyle.Render(badge) +
+		border.Render(" ┙")
+	return strings.Join(lines, "\n")
+}
+
+// runAge is a compact "how long ago": now, 12m, 5h, 3d.
+func runAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 func columnToLetter(col int) string {
 	if col < 0 || col > 25 {
 		return "?"
@@ -120,8 +253,26 @@ func (m Model) renderProfileCounter() string {
 	if x > 9 {
 		x = 9
 	}
-	counter := fmt.Sprintf("< %d / %d >", x, y)
-	return titleStyle.Render(counter)
+	line := titleStyle.Render(fmt.Sprintf("< %d / %d >", x, y))
+	if target := m.remoteTarget(); target != "" {
+		line += "  " + statusNegativeStyle.Bold(true).Render(target)
+	}
+	if m.DryRun {
+		line += "  " + statusPositiveStyle.Bold(true).Render("DRY RUN")
+	}
+	return line
+}
+
+// remoteTarget labels the host(s) the active profile runs its commands on, if any.
+func (m Model) remoteTarget() string {
+	switch len(m.Config.Hosts) {
+	case 0:
+		return ""
+	case 1:
+		return "⇄ " + m.Config.Hosts[0]
+	default:
+		return "⇄ " + strings.Join(m.Config.Hosts, ", ") + " (picked on run)"
+	}
 }
 
 func (m Model) renderProfileBar() string {
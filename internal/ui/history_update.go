@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+// historyLoadLimit caps how many entries the browser loads from the store.
+const historyLoadLimit = 1000
+
+// historyOutputLines is how many lines of a debug capture the browser shows.
+const historyOutputLines = 15
+
+// historyModel holds the state of the execution history browser.
+type historyModel struct {
+	all        []core.HistoryEntry // Newest first, as loaded
+	visible    []core.HistoryEntry // all, narrowed by query
+	cursor     int
+	query      string
+	editing    bool // Keys go to the filter input
+	showOutput bool // Show the captured output of the selected entry
+	output     []string
+	err        string
+	returnMode navMode
+}
+
+func (m Model) openHistoryBrowser() (tea.Model, tea.Cmd) {
+	m.history = historyModel{returnMode: m.mode}
+	entries, err := core.ReadHistory(core.HistoryFilter{Limit: historyLoadLimit})
+	if err != nil {
+		m.history.err = err.Error()
+	}
+	m.history.all = entries
+	m.applyHistoryFilter()
+	m.mode = historyMode
+	return m, nil
+}
+
+// applyHistoryFilter recomputes the visible entries from the current query.
+func (m *Model) applyHistoryFilter() {
+	h := &m.history
+	h.err = ""
+	filter, err := core.ParseHistoryQuery(h.query, time.Now())
+	if err != nil {
+		h.err = err.Error()
+		return
+	}
+
+	var visible []core.HistoryEntry
+	for _, e := range h.all {
+		if filter.Match(e) {
+			visible = append(visible, e)
+		}
+	}
+	h.visible = visible
+	if h.cursor >= len(h.visible) {
+		h.cursor = len(h.visible) - 1
+	}
+	if h.cursor < 0 {
+		h.cursor = 0
+	}
+	m.refreshHistoryOutput()
+}
+
+// refreshHistoryOutput loads the capture tail of the selected entry, if it is shown.
+func (m *Model) refreshHistoryOutput() {
+	h := &m.history
+	h.output = nil
+	if !h.showOutput {
+		return
+	}
+	e, ok := m.selectedHistoryEntry()
+	if !ok || e.Output == "" {
+		return
+	}
+	lines, err := core.ReadCapture(e.Output, historyOutputLines)
+	if err != nil {
+		h.err = "output: " + err.Error()
+		return
+	}
+	h.output = lines
+}
+
+func (m Model) selectedHistoryEntry() (core.HistoryEntry, bool) {
+	if m.history.cursor < 0 || m.history.cursor >= len(m.history.visible) {
+		return core.HistoryEntry{}, false
+	}
+	return m.history.visible[m.history.cursor], true
+}
+
+func (m Model) updateHistoryMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.history.editing {
+		return m.updateHistoryFilterInput(msg)
+	}
+
+	switch {
+	case IsCancel(m.Config.Keys, msg), IsHistory(m.Config.Keys, msg):
+		if m.history.showOutput {
+			m.history.showOutput = false
+			m.history.output = nil
+			return m, nil
+		}
+		m.mode = m.history.returnMode
+		return m, nil
+	case IsUp(m.Config.Keys, msg):
+		if m.history.cursor > 0 {
+			m.history.cursor--
+			m.refreshHistoryOutput()
+		}
+	case IsDown(m.Config.Keys, msg):
+		if m.history.cursor < len(m.history.visible)-1 {
+			m.history.cursor++
+			m.refreshHistoryOutput()
+		}
+	case msg.String() == "o":
+		m.history.showOutput = !m.history.showOutput
+		m.refreshHistoryOutput()
+	case msg.String() == "v":
+		if e, ok := m.selectedHistoryEntry(); ok {
+			if e.Output == "" {
+				m.history.err = "no captured output (only debug runs are captured)"
+				return m, nil
+			}
+			return m.OpenOutput(e), nil
+		}
+	case msg.String() == "/":
+		m.history.editing = true
+	case msg.String() == "enter":
+		// Re-run the entry exactly as recorded, in its original directory
+		if e, ok := m.selectedHistoryEntry(); ok {
+			m.Replay = &e
+			m.Parent = ""
+			return m.startExecution(e.Cell)
+		}
+	case msg.String() == "y":
+		if m.GlassrootMode {
+			return m, nil
+		}
+		if e, ok := m.selectedHistoryEntry(); ok {
+			return m, tea.Batch(copyToClipboardCmd(e.CommandLine()), m.setProfileStatus("Copied command", true))
+		}
+	case msg.String() == "g":
+		if e, ok := m.selectedHistoryEntry(); ok {
+			return m.jumpToCell(e)
+		}
+	}
+	return m, nil
+}
+
+// updateHistoryFilterInput edits the filter query; the list narrows as you type.
+func (m Model) updateHistoryFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.history.editing = false
+		return m, nil
+	case tea.KeyEsc:
+		m.history.editing = false
+		m.history.query = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.history.query); len(r) > 0 {
+			m.history.query = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlU:
+		m.history.query = ""
+	case tea.KeySpace:
+		m.history.query += " "
+	case tea.KeyRunes:
+		m.history.query += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.applyHistoryFilter()
+	return m, nil
+}
+
+// jumpToCell switches to the entry's profile (if it still exists) and puts the cursor on
+// the cell that produced it. Dropdown items resolve to their parent cell.
+func (m Model) jumpToCell(e core.HistoryEntry) (tea.Model, tea.Cmd) {
+	if e.Profile != "" && !strings.EqualFold(e.Profile, m.activeProfileName()) {
+		switched := false
+		for i, p := range m.profiles {
+			if strings.EqualFold(p.Name, e.Profile) {
+				if updated, _, ok := m.switchToProfileIndex(i); ok {
+					m = updated
+					switched = true
+				}
+				break
+			}
+		}
+		if !switched {
+			m.history.err = "profile " + e.Profile + " is not equipped"
+			return m, nil
+		}
+	}
+
+	target := e.Cell
+	if parent, item, found := core.FindCommandByName(m.Config, e.Cell); found && item != nil {
+		target = parent.Name
+	}
+	for r, row := range m.grid {
+		for c, name := range row {
+			if name == target {
+				m.cursorRow, m.cursorCol = r, c
+				m.mode = gridMode
+				return m, nil
+			}
+		}
+	}
+
+	m.mode = gridMode
+	return m, m.setProfileStatus("Cell "+e.Cell+" not found", false)
+}
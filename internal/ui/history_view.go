@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+func (m Model) viewHistoryMode() string {
+	layout := CalculateLayout(m.termWidth, m.termHeight, m.Config)
+	h := m.history
+
+	var s strings.Builder
+	if layout.ShowHeader {
+		s.WriteString(inventoryTitleStyle.Render("Execution History") + "\n\n")
+	}
+
+	filterLine := helpStyle.Render("Filter: ")
+	if h.editing {
+		filterLine += selectedItemStyle.Render(h.query + "_")
+	} else if h.query != "" {
+		filterLine += itemStyle.Render(h.query)
+	} else {
+		filterLine += helpStyle.Render("(press / — e.g. profile:git status:fail date:yesterday deploy)")
+	}
+	s.WriteString(filterLine + "\n\n")
+
+	// Leave room for title, filter, details and help.
+	rows := m.termHeight - 16
+	if h.showOutput {
+		rows -= historyOutputLines + 2
+	}
+	if rows < 3 {
+		rows = 3
+	}
+	start := 0
+	if h.cursor >= rows {
+		start = h.cursor - rows + 1
+	}
+	end := start + rows
+	if end > len(h.visible) {
+		end = len(h.visible)
+	}
+
+	if len(h.visible) == 0 {
+		s.WriteString(helpStyle.Render("No matching executions."))
+	} else {
+		var lines []string
+		for i := start; i < end; i++ {
+			e := h.visible[i]
+			cell := e.Cell
+			if e.Step != "" {
+				cell += " › " + e.Step
+			}
+			line := fmt.Sprintf("%s  %4s  %8s  %-12s %s",
+				e.Start.Format("2006-01-02 15:04"), historyExitLabel(e), formatJobDuration(e.Duration()), truncateRunes(e.Profile, 12), cell)
+			badge := statusPositiveStyle.Render("✔")
+			if !e.Succeeded() {
+				badge = statusNegativeStyle.Render("✘")
+			}
+			if i == h.cursor {
+				lines = append(lines, selectedCursorStyle.Render("> ")+badge+" "+selectedItemStyle.Render(line))
+			} else {
+				lines = append(lines, "  "+badge+" "+itemStyle.Render(line))
+			}
+		}
+		s.WriteString(lipgloss.JoinVertical(lipgloss.Left, lines...))
+		s.WriteString("\n" + helpStyle.Render(fmt.Sprintf("%d of %d", h.cursor+1, len(h.visible))))
+	}
+
+	if e, ok := m.selectedHistoryEntry(); ok {
+		s.WriteString("\n\n")
+		s.WriteString(helpStyle.Render("Command: ") + itemStyle.Render(e.CommandLine()) + "\n")
+		s.WriteString(helpStyle.Render("CWD: ") + itemStyle.Render(e.Cwd) + "\n")
+		s.WriteString(helpStyle.Render("Mode: ") + itemStyle.Render(e.Mode))
+		if e.Error != "" {
+			s.WriteString(helpStyle.Render("  Error: ") + errorTextStyle.Render(e.Error))
+		}
+		if e.Output != "" {
+			s.WriteString("\n" + helpStyle.Render("Output: ") + itemStyle.Render(e.Output))
+		}
+		if h.showOutput {
+			s.WriteString("\n\n")
+			switch {
+			case e.Output == "":
+				s.WriteString(helpStyle.Render("No captured output (only debug runs are captured)."))
+			case len(h.output) == 0:
+				s.WriteString(helpStyle.Render("(empty)"))
+			default:
+				s.WriteString(itemStyle.Render(strings.Join(h.output, "\n")))
+			}
+		}
+	}
+
+	if h.err != "" {
+		s.WriteString("\n\n" + errorTextStyle.Render(h.err))
+	}
+
+	if layout.ShowFooter {
+		help := helpStyle.Render("↑/↓: Select | /: Filter | Enter: Re-run | o: Output | v: View output | y: Copy | g: Go to cell | q/esc: Back")
+		s.WriteString(footerStyle.Render(help))
+	}
+
+	return appStyle.Render(
+		lipgloss.Place(m.termWidth, m.termHeight, lipgloss.Center, lipgloss.Center, s.String()),
+	)
+}
+
+func historyExitLabel(e core.HistoryEntry) string {
+	if e.ExitCode < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", e.ExitCode)
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -62,6 +62,26 @@ func IsInventory(c config.InputConfig, msg tea.KeyMsg) bool {
 	return msg.String() == c.Inventory
 }
 
+// IsJobs checks if the key matches the jobs panel action.
+func IsJobs(c config.InputConfig, msg tea.KeyMsg) bool {
+	return msg.String() == c.Jobs
+}
+
+// IsHistory checks if the key matches the history browser action.
+func IsHistory(c config.InputConfig, msg tea.KeyMsg) bool {
+	return msg.String() == c.History
+}
+
+// IsOutput checks if the key matches the output viewer action.
+func IsOutput(c config.InputConfig, msg tea.KeyMsg) bool {
+	return msg.String() == c.Output
+}
+
+// IsDryRun checks if the key matches the dry-run toggle.
+func IsDryRun(c config.InputConfig, msg tea.KeyMsg) bool {
+	return msg.String() == c.DryRun
+}
+
 // IsPathGridMode checks if the key matches the path/grid toggle action.
 func IsPathGridMode(c config.InputConfig, msg tea.KeyMsg) bool {
 	return msg.String() == c.PathGridMode
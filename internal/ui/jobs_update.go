@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+// jobsTailLines is how many log lines the jobs panel shows when tailing a job.
+const jobsTailLines = 15
+
+type jobsTickMsg struct{}
+
+// jobsModel holds the state of the background jobs panel.
+type jobsModel struct {
+	list       []core.Job
+	cursor     int
+	tailing    bool // Show the log tail of the selected job
+	tail       []string
+	err        string
+	returnMode navMode
+}
+
+// jobsTick refreshes the jobs panel once a second while it is open.
+func jobsTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return jobsTickMsg{}
+	})
+}
+
+func (m Model) openJobsPanel() (tea.Model, tea.Cmd) {
+	m.jobs = jobsModel{returnMode: m.mode}
+	m.refreshJobs()
+	m.mode = jobsMode
+	return m, jobsTick()
+}
+
+// refreshJobs reloads the job list (and the tail, if shown) from the job manager.
+func (m *Model) refreshJobs() {
+	m.jobs.list = core.Jobs()
+	if m.jobs.cursor >= len(m.jobs.list) {
+		m.jobs.cursor = len(m.jobs.list) - 1
+	}
+	if m.jobs.cursor < 0 {
+		m.jobs.cursor = 0
+	}
+
+	m.jobs.tail = nil
+	if m.jobs.tailing {
+		if j, ok := m.selectedJob(); ok {
+			lines, err := core.TailJobLog(j.ID, jobsTailLines)
+			if err != nil {
+				m.jobs.err = err.Error()
+			}
+			m.jobs.tail = lines
+		}
+	}
+}
+
+func (m Model) selectedJob() (core.Job, bool) {
+	if m.jobs.cursor < 0 || m.jobs.cursor >= len(m.jobs.list) {
+		return core.Job{}, false
+	}
+	return m.jobs.list[m.jobs.cursor], true
+}
+
+func (m Model) updateJobsMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.jobs.err = ""
+
+	switch {
+	case IsCancel(m.Config.Keys, msg), IsJobs(m.Config.Keys, msg):
+		if m.jobs.tailing {
+			m.jobs.tailing = false
+			m.jobs.tail = nil
+			return m, nil
+		}
+		m.mode = m.jobs.returnMode
+		return m, nil
+	case IsUp(m.Config.Keys, msg):
+		if m.jobs.cursor > 0 {
+			m.jobs.cursor--
+		}
+	case IsDown(m.Config.Keys, msg):
+		if m.jobs.cursor < len(m.jobs.list)-1 {
+			m.jobs.cursor++
+		}
+	case IsConfirm(m.Config.Keys, msg), msg.String() == "t":
+		m.jobs.tailing = !m.jobs.tailing
+	case msg.String() == "x":
+		if j, ok := m.selectedJob(); ok {
+			if err := core.CancelJob(j.ID); err != nil {
+				m.jobs.err = err.Error()
+			}
+		}
+	case msg.String() == "r":
+		if j, ok := m.selectedJob(); ok {
+			return m.rerunJob(j)
+		}
+	}
+
+	m.refreshJobs()
+	return m, nil
+}
+
+// rerunJob starts a job's cell again through startExecution, with the current config and the
+// job's parameters and host, so confirmation, dry run and requirements apply as usual.
+func (m Model) rerunJob(j core.Job) (tea.Model, tea.Cmd) {
+	opts, err := core.JobRunOptions(j.ID)
+	if err != nil {
+		m.jobs.err = err.Error()
+		return m, nil
+	}
+	if opts.Profile != m.activeProfileName() {
+		m.jobs.err = fmt.Sprintf("Job #%d ran in profile %s; switch to it to re-run", j.ID, opts.Profile)
+		return m, nil
+	}
+	if _, _, found := core.FindCommandIn(m.Config, opts.Parent, j.Name); !found {
+		m.jobs.err = fmt.Sprintf("%s is no longer in this profile", j.Name)
+		return m, nil
+	}
+
+	m.jobs.err = ""
+	m.Params = opts.Params
+	m.Host = opts.Host
+	m.Parent = opts.Parent
+	next, cmd := m.startExecution(j.Name)
+	nm := next.(Model)
+	// A background cell started (or was refused) without leaving the panel.
+	if nm.mode == gridMode {
+		nm.mode = jobsMode
+		nm.jobs.cursor = 0
+		nm.jobs.tailing = false
+		nm.refreshJobs()
+	}
+	return nm, cmd
+}
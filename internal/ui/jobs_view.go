@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+func (m Model) viewJobsMode() string {
+	layout := CalculateLayout(m.termWidth, m.termHeight, m.Config)
+
+	var s strings.Builder
+	if layout.ShowHeader {
+		s.WriteString(inventoryTitleStyle.Render("Background Jobs") + "\n\n")
+	}
+
+	if len(m.jobs.list) == 0 {
+		s.WriteString(helpStyle.Render("No jobs yet. Set background = true on a command to run it here."))
+	} else {
+		var rows []string
+		for i, j := range m.jobs.list {
+			line := fmt.Sprintf("#%-3d %-9s %8s  exit %-4s %s", j.ID, j.Status, formatJobDuration(j.Duration()), jobExitLabel(j), j.Name)
+			style := itemStyle
+			if i == m.jobs.cursor {
+				style = selectedItemStyle
+				line = "> " + line
+			} else {
+				line = "  " + line
+			}
+			rows = append(rows, style.Render(line)+" "+jobStatusBadge(j))
+		}
+		s.WriteString(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	}
+
+	if m.jobs.tailing {
+		s.WriteString("\n\n")
+		if j, ok := m.selectedJob(); ok {
+			s.WriteString(listHeaderStyle.Render("Log: "+j.LogPath) + "\n")
+		}
+		wrapWidth := m.termWidth - 10
+		if wrapWidth < 20 {
+			wrapWidth = 20
+		}
+		var tail []string
+		for _, ln := range m.jobs.tail {
+			tail = append(tail, WrapText(ln, wrapWidth)...)
+		}
+		if len(tail) > jobsTailLines {
+			tail = tail[len(tail)-jobsTailLines:]
+		}
+		s.WriteString(dropdownPopupStyle.Render(strings.Join(tail, "\n")))
+	}
+
+	if m.jobs.err != "" {
+		s.WriteString("\n\n" + errorTextStyle.Render(m.jobs.err))
+	}
+
+	if layout.ShowFooter {
+		help := helpStyle.Render("↑/↓: Select | Enter/t: Tail log | x: Cancel | r: Re-run | q/esc: Back")
+		s.WriteString(footerStyle.Render(help))
+	}
+
+	return appStyle.Render(
+		lipgloss.Place(m.termWidth, m.termHeight, lipgloss.Center, lipgloss.Center, s.String()),
+	)
+}
+
+func jobExitLabel(j core.Job) string {
+	if j.Status == core.JobRunning || j.ExitCode < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", j.ExitCode)
+}
+
+func jobStatusBadge(j core.Job) string {
+	switch j.Status {
+	case core.JobSucceeded:
+		return statusPositiveStyle.Render("✔")
+	case core.JobFailed, core.JobCancelled:
+		return statusNegativeStyle.Render("✘")
+	default:
+		return ""
+	}
+}
+
+// formatJobDuration renders a duration as m:ss (or h:mm:ss for long jobs).
+func formatJobDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d / time.Hour)
+	mins := int(d%time.Hour) / int(time.Minute)
+	secs := int(d%time.Minute) / int(time.Second)
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, mins, secs)
+	}
+	return fmt.Sprintf("%d:%02d", mins, secs)
+}
@@ -11,6 +11,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lucky7xz/drako/internal/config"
+	"github.com/lucky7xz/drako/internal/core"
 )
 
 const (
@@ -26,6 +27,11 @@ type Model struct {
 	termWidth   int
 	termHeight  int
 	Selected    string
+	Params      map[string]string  // Values collected by the parameter prompt for Selected
+	Replay      *core.HistoryEntry // Set when Selected is a re-run from the history browser
+	Host        string             // Target host picked in the parameter prompt (profiles with several hosts)
+	Parent      string             // Dropdown cell of Selected when it is one of its items
+	DryRun      bool               // Selecting a cell shows what would run instead of running it
 	Quitting    bool
 	mode        navMode
 	spinner     spinner.Model
@@ -63,6 +69,26 @@ type Model struct {
 	dropdownCol         int
 	dropdownSelectedIdx int
 	dropdownItems       []config.CommandItem
+	dropdownCell        string // Cell whose dropdown is open
+	dropdownLoading     bool   // items_command is still running
+	dropdownErr         string // Why items_command produced no items
+
+	paramForm paramFormModel
+
+	confirm   confirmModel
+	confirmed bool // Set once the confirmation prompt was accepted for the pending execution
+
+	checkingContainer string // Cell waiting for its container check
+	containerChecked  string // Cell whose container was found running for the pending execution
+
+	jobs jobsModel
+
+	history historyModel
+	output  outputModel
+
+	lastRuns map[string]core.HistoryEntry // Newest history entry per cell of the active profile
+	probing  map[string]bool              // Cells whose status_command is running
+	checking map[string]bool              // requires.check commands that are running
 
 	previousMode navMode
 	activeDetail *DetailState // Single source of truth for detail view
@@ -83,8 +109,34 @@ type Model struct {
 func (m *Model) applyConfig(cfg config.Config) {
 	config.ClampConfig(&cfg)
 	applyThemeStyles(cfg)
+	m.Config = cfg
+	m.applyRequirements()
+	m.inputBuffer = ""
+	if m.spinner.Spinner.Frames == nil {
+		m.spinner = spinner.New()
+		m.spinner.Spinner = spinner.Line
+	}
+	m.spinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+
+	// Initialize lock timeout (default minutes if not set)
+	if cfg.LockTimeoutMinutes != nil && *cfg.LockTimeoutMinutes > 0 {
+		m.lockTimeoutMins = *cfg.LockTimeoutMinutes
+	} else {
+		m.lockTimeoutMins = defaultLockTimeoutMinutes
+	}
+
+	if m.lockPumpGoal <= 0 {
+		m.lockPumpGoal = defaultLockPumpGoal
+	}
+
+	m.refreshLastRuns()
+}
 
-	m.grid = config.BuildGrid(cfg)
+// applyRequirements marks the cells that cannot run here and rebuilds the grid around the
+// ones that are hidden. It is called again whenever a requires.check reports.
+func (m *Model) applyRequirements() {
+	m.Config.Commands = core.ApplyRequirements(m.Config)
+	m.grid = config.BuildGrid(m.Config)
 	if len(m.grid) > 0 {
 		if m.cursorRow >= len(m.grid) {
 			m.cursorRow = len(m.grid) - 1
@@ -101,24 +153,15 @@ func (m *Model) applyConfig(cfg config.Config) {
 			}
 		}
 	}
-	m.Config = cfg
-	m.inputBuffer = ""
-	if m.spinner.Spinner.Frames == nil {
-		m.spinner = spinner.New()
-		m.spinner.Spinner = spinner.Line
-	}
-	m.spinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
-
-	// Initialize lock timeout (default minutes if not set)
-	if cfg.LockTimeoutMinutes != nil && *cfg.LockTimeoutMinutes > 0 {
-		m.lockTimeoutMins = *cfg.LockTimeoutMinutes
-	} else {
-		m.lockTimeoutMins = defaultLockTimeoutMinutes
-	}
+}
 
-	if m.lockPumpGoal <= 0 {
-		m.lockPumpGoal = defaultLockPumpGoal
+// refreshLastRuns reloads the last outcome of every cell in the active profile from history.
+func (m *Model) refreshLastRuns() {
+	last, err := core.LastRuns(m.activeProfileName())
+	if err != nil {
+		log.Printf("could not load last runs: %v", err)
 	}
+	m.lastRuns = last
 }
 
 func (m *Model) applyBundle(bundle config.ConfigBundle) {
@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+// outputMaxLines caps how much of a capture the viewer loads; longer captures show their tail.
+const outputMaxLines = 10000
+
+// outputModel holds the state of the captured-output viewer.
+type outputModel struct {
+	entry      core.HistoryEntry
+	lines      []string // Capture content, ANSI colors intact
+	viewport   viewport.Model
+	query      string
+	searching  bool  // Keys go to the search input
+	matches    []int // Indices of the lines matching query
+	match      int   // Current entry in matches
+	err        string
+	returnMode navMode
+}
+
+// OpenOutput shows the captured output of a history entry in the viewer.
+func (m Model) OpenOutput(e core.HistoryEntry) Model {
+	m.output = outputModel{entry: e, returnMode: m.mode}
+	lines, err := core.ReadCapture(e.Output, outputMaxLines)
+	if err != nil {
+		m.output.err = err.Error()
+	}
+	for i, ln := range lines {
+		// Progress bars redraw with \r; only the final state of the line is worth showing.
+		if j := strings.LastIndex(ln, "\r"); j >= 0 {
+			lines[i] = ln[j+1:]
+		}
+	}
+	m.output.lines = lines
+	m.output.viewport = viewport.New(0, 0)
+	m.mode = outputMode
+	m.resizeOutputViewer()
+	return m
+}
+
+// resizeOutputViewer fits the viewport between the title and the status lines.
+func (m *Model) resizeOutputViewer() {
+	if m.mode != outputMode {
+		return
+	}
+	layout := CalculateLayout(m.termWidth, m.termHeight, m.Config)
+	height := m.termHeight - 6
+	if layout.ShowHeader {
+		height -= 2
+	}
+	if height < 3 {
+		height = 3
+	}
+	width := m.termWidth - 4
+	if width < 20 {
+		width = 20
+	}
+	m.output.viewport.Width = width
+	m.output.viewport.Height = height
+	m.renderOutputContent()
+}
+
+// renderOutputContent puts the lines into the viewport, marking the ones that match the search.
+func (m *Model) renderOutputContent() {
+	o := &m.output
+	matched := make(map[int]bool, len(o.matches))
+	for _, i := range o.matches {
+		matched[i] = true
+	}
+	current := -1
+	if len(o.matches) > 0 {
+		current = o.matches[o.match]
+	}
+
+	var b strings.Builder
+	for i, ln := range o.lines {
+		switch {
+		case i == current:
+			b.WriteString(selectedCursorStyle.Render("▶ "))
+		case matched[i]:
+			b.WriteString(selectedItemStyle.Render("▌ "))
+		default:
+			b.WriteString("  ")
+		}
+		b.WriteString(ln)
+		if i < len(o.lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	o.viewport.SetContent(b.String())
+}
+
+// applyOutputSearch finds the lines containing the query (case-insensitive, colors ignored)
+// and scrolls to the first match.
+func (m *Model) applyOutputSearch() {
+	o := &m.output
+	o.matches = nil
+	o.match = 0
+	if q := strings.ToLower(o.query); q != "" {
+		for i, ln := range o.lines {
+			if strings.Contains(strings.ToLower(ansi.Strip(ln)), q) {
+				o.matches = append(o.matches, i)
+			}
+		}
+	}
+	m.renderOutputContent()
+	m.scrollToMatch()
+}
+
+// scrollToMatch keeps the current match in view, a few lines below the top.
+func (m *Model) scrollToMatch() {
+	o := &m.output
+	if len(o.matches) == 0 {
+		return
+	}
+	line := o.matches[o.match] - 2
+	if line < 0 {
+		line = 0
+	}
+	o.viewport.SetYOffset(line)
+}
+
+// stepOutputMatch moves to the next (dir 1) or previous (dir -1) match, wrapping around.
+func (m *Model) stepOutputMatch(dir int) {
+	o := &m.output
+	if len(o.matches) == 0 {
+		return
+	}
+	o.match = (o.match + dir + len(o.matches)) % len(o.matches)
+	m.renderOutputContent()
+	m.scrollToMatch()
+}
+
+func (m Model) updateOutputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.output.searching {
+		return m.updateOutputSearchInput(msg)
+	}
+
+	switch {
+	case IsCancel(m.Config.Keys, msg), IsOutput(m.Config.Keys, msg):
+		m.mode = m.output.returnMode
+		m.output = outputModel{}
+		return m, nil
+	case msg.String() == "e", msg.String() == "/":
+		m.output.searching = true
+		return m, nil
+	case msg.String() == "n":
+		m.stepOutputMatch(1)
+		return m, nil
+	case msg.String() == "N":
+		m.stepOutputMatch(-1)
+		return m, nil
+	case msg.String() == "g", msg.String() == "home":
+		m.output.viewport.GotoTop()
+		return m, nil
+	case msg.String() == "G", msg.String() == "end":
+		m.output.viewport.GotoBottom()
+		return m, nil
+	case msg.String() == "y":
+		if m.GlassrootMode {
+			return m, nil
+		}
+		plain := make([]string, len(m.output.lines))
+		for i, ln := range m.output.lines {
+			plain[i] = ansi.Strip(ln)
+		}
+		return m, tea.Batch(copyToClipboardCmd(strings.Join(plain, "\n")), m.setProfileStatus("Copied output", true))
+	}
+
+	var cmd tea.Cmd
+	m.output.viewport, cmd = m.output.viewport.Update(msg)
+	return m, cmd
+}
+
+// updateOutputSearchInput edits the search query; matches update as you type.
+func (m Model) updateOutputSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.output.searching = false
+		return m, nil
+	case tea.KeyEsc:
+		m.output.searching = false
+		m.output.query = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.output.query); len(r) > 0 {
+			m.output.query = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlU:
+		m.output.query = ""
+	case tea.KeySpace:
+		m.output.query += " "
+	case tea.KeyRunes:
+		m.output.query += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.applyOutputSearch()
+	return m, nil
+}
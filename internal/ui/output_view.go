@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m Model) viewOutputMode() string {
+	layout := CalculateLayout(m.termWidth, m.termHeight, m.Config)
+	o := m.output
+
+	var s strings.Builder
+	if layout.ShowHeader {
+		title := "Output: " + o.entry.Cell
+		if o.entry.Step != "" {
+			title += " › " + o.entry.Step
+		}
+		s.WriteString(inventoryTitleStyle.Render(title) + "\n\n")
+	}
+
+	if o.err != "" {
+		s.WriteString(errorTextStyle.Render(o.err) + "\n")
+	} else {
+		s.WriteString(o.viewport.View() + "\n")
+	}
+
+	status := helpStyle.Render(fmt.Sprintf("%s  %3.0f%%", o.entry.Start.Format("2006-01-02 15:04"), o.viewport.ScrollPercent()*100))
+	switch {
+	case o.searching:
+		status += helpStyle.Render("  Search: ") + selectedItemStyle.Render(o.query+"_")
+	case o.query != "":
+		found := "no matches"
+		if len(o.matches) > 0 {
+			found = fmt.Sprintf("%d/%d", o.match+1, len(o.matches))
+		}
+		status += helpStyle.Render("  Search: ") + itemStyle.Render(o.query) + helpStyle.Render(" ("+found+")")
+	}
+	s.WriteString(status)
+
+	if layout.ShowFooter {
+		help := helpStyle.Render("↑/↓/PgUp/PgDn: Scroll | g/G: Top/Bottom | e or /: Search | n/N: Next/Prev | y: Copy | q/esc: Back")
+		s.WriteString(footerStyle.Render(help))
+	}
+
+	return appStyle.Render(
+		lipgloss.Place(m.termWidth, m.termHeight, lipgloss.Center, lipgloss.Center, s.String()),
+	)
+}
@@ -50,6 +50,12 @@ func (m Model) viewDropdownMode() string {
 	mainContent := lipgloss.JoinVertical(lipgloss.Center, header, grid)
 
 	helpText := "Dropdown Mode | ↑/↓/ws: Select, Enter: Execute, Esc/q: Cancel"
+	for _, cmd := range m.Config.Commands {
+		if cmd.Name == m.dropdownCell && strings.TrimSpace(cmd.ItemsCommand) != "" {
+			helpText += ", Ctrl+R: Reload"
+			break
+		}
+	}
 	help := helpStyle.Render(helpText)
 
 	// Adjust footer rendering for layout?
@@ -129,15 +135,29 @@ func (m Model) renderDropdownPopup() string {
 	for i, item := range m.dropdownItems {
 		var line string
 		if i == m.dropdownSelectedIdx {
-			line = cursorSel.Render("► ") + textSel.Render(item.Name)
+			line = cursorSel.Render("► ") + textSel.Faint(item.Unmet != "").Render(item.Name)
 		} else {
-			line = gap.Render("  ") + textNorm.Render(item.Name)
+			line = gap.Render("  ") + textNorm.Faint(item.Unmet != "").Render(item.Name)
 		}
 		raw = append(raw, line)
 		if w := lipgloss.Width(line); w > maxW {
 			maxW = w
 		}
 	}
+	// Dynamic dropdowns report their items_command state below the items.
+	var status string
+	switch {
+	case m.dropdownLoading:
+		status = helpStyle.Background(bg).Render("  Loading items…")
+	case m.dropdownErr != "":
+		status = errorTextStyle.Background(bg).Render("  ✘ " + truncateText(m.dropdownErr, 60))
+	}
+	if status != "" {
+		raw = append(raw, status)
+		if w := lipgloss.Width(status); w > maxW {
+			maxW = w
+		}
+	}
 	if maxW == 0 {
 		maxW = 1
 	}
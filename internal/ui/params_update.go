@@ -0,0 +1,141 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucky7xz/drako/internal/config"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+// paramFormModel holds the state of the parameter prompt shown before a command runs.
+type paramFormModel struct {
+	target     string // Name of the cell that will run once the form is submitted
+	params     []config.CommandParam
+	values     []string
+	cursor     int
+	err        string
+	returnMode navMode // Mode to go back to on cancel (grid or dropdown)
+}
+
+// hostParamName is the form field for picking the target host. It cannot clash with a
+// declared parameter because it is not a valid placeholder name.
+const hostParamName = "@host"
+
+// newParamForm builds the prompt for spec's parameters. With hosts, a host choice comes first.
+func newParamForm(spec core.CommandSpec, hosts []string, returnMode navMode) paramFormModel {
+	var params []config.CommandParam
+	if len(hosts) > 0 {
+		params = append(params, config.CommandParam{Name: hostParamName, Type: core.ParamChoice, Prompt: "Host", Choices: hosts})
+	}
+	params = append(params, spec.Params...)
+
+	values := make([]string, len(params))
+	for i, p := range params {
+		values[i] = core.ParamDefault(p)
+	}
+	return paramFormModel{
+		target:     spec.Name,
+		params:     params,
+		values:     values,
+		returnMode: returnMode,
+	}
+}
+
+// cycleChoice moves the focused choice parameter by dir, wrapping around.
+func (f *paramFormModel) cycleChoice(dir int) {
+	p := f.params[f.cursor]
+	if len(p.Choices) == 0 {
+		return
+	}
+	idx := 0
+	for i, c := range p.Choices {
+		if c == f.values[f.cursor] {
+			idx = i
+			break
+		}
+	}
+	idx = ((idx+dir)%len(p.Choices) + len(p.Choices)) % len(p.Choices)
+	f.values[f.cursor] = p.Choices[idx]
+}
+
+// collect validates the form and returns the values keyed by parameter name.
+func (f *paramFormModel) collect() (map[string]string, error) {
+	raw := make(map[string]string, len(f.params))
+	for i, p := range f.params {
+		raw[p.Name] = f.values[i]
+	}
+	if _, err := core.ResolveParamValues(f.params, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// updateParamMode handles key input while the parameter prompt is open.
+// Text is typed directly into the focused field, so navigation is limited to arrows and tab.
+func (m Model) updateParamMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &m.paramForm
+	if len(f.params) == 0 {
+		m.mode = f.returnMode
+		return m, nil
+	}
+	isChoice := core.ParamType(f.params[f.cursor]) == core.ParamChoice
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = f.returnMode
+		m.paramForm = paramFormModel{}
+		return m, nil
+	case tea.KeyUp, tea.KeyShiftTab:
+		if f.cursor > 0 {
+			f.cursor--
+		}
+	case tea.KeyDown, tea.KeyTab:
+		if f.cursor < len(f.params)-1 {
+			f.cursor++
+		}
+	case tea.KeyLeft:
+		if isChoice {
+			f.cycleChoice(-1)
+		}
+	case tea.KeyRight:
+		if isChoice {
+			f.cycleChoice(1)
+		}
+	case tea.KeyEnter:
+		if f.cursor < len(f.params)-1 {
+			f.cursor++
+			return m, nil
+		}
+		values, err := f.collect()
+		if err != nil {
+			f.err = err.Error()
+			return m, nil
+		}
+		if host, ok := values[hostParamName]; ok {
+			m.Host = host
+			delete(values, hostParamName)
+		}
+		m.Params = values
+		m.mode = f.returnMode
+		return m.startExecution(f.target)
+	case tea.KeyBackspace:
+		if !isChoice {
+			if r := []rune(f.values[f.cursor]); len(r) > 0 {
+				f.values[f.cursor] = string(r[:len(r)-1])
+			}
+		}
+	case tea.KeyCtrlU:
+		if !isChoice {
+			f.values[f.cursor] = ""
+		}
+	case tea.KeySpace:
+		if !isChoice {
+			f.values[f.cursor] += " "
+		}
+	case tea.KeyRunes:
+		if !isChoice {
+			f.values[f.cursor] += string(msg.Runes)
+		}
+	}
+	f.err = ""
+	return m, nil
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucky7xz/drako/internal/core"
+)
+
+func (m Model) viewParamMode() string {
+	layout := CalculateLayout(m.termWidth, m.termHeight, m.Config)
+	header := ""
+	if layout.ShowHeader {
+		header = renderHeaderArt(m.spinner.View())
+	}
+
+	f := m.paramForm
+	bg := dropdownPopupStyle.GetBackground()
+	bgFill := lipgloss.NewStyle().Background(bg)
+	titleStyleLocal := titleStyle.Background(bg)
+	labelStyle := helpStyle.Background(bg)
+	textNorm := itemStyle.Background(bg)
+	textSel := selectedItemStyle.Background(bg)
+	cursorSel := selectedCursorStyle.Background(bg)
+	errStyle := errorTextStyle.Background(bg)
+
+	var raw []string
+	raw = append(raw, titleStyleLocal.Render(f.target))
+	raw = append(raw, "")
+
+	for i, p := range f.params {
+		label := p.Prompt
+		if strings.TrimSpace(label) == "" {
+			label = p.Name
+		}
+
+		value := f.values[i]
+		if core.ParamType(p) == core.ParamChoice {
+			value = "‹ " + value + " ›"
+		}
+
+		if i == f.cursor {
+			if core.ParamType(p) != core.ParamChoice {
+				value += "_"
+			}
+			raw = append(raw, cursorSel.Render("> ")+labelStyle.Render(label+": ")+textSel.Render(value))
+		} else {
+			raw = append(raw, bgFill.Render("  ")+labelStyle.Render(label+": ")+textNorm.Render(value))
+		}
+	}
+
+	if f.err != "" {
+		raw = append(raw, "")
+		raw = append(raw, errStyle.Render(f.err))
+	}
+
+	raw = append(raw, "")
+	raw = append(raw, helpStyle.Render("Tab/↑↓: Field • ←/→: Choice • Enter: Next/Run • Esc: Cancel"))
+
+	maxW := 0
+	for _, line := range raw {
+		if w := lipgloss.Width(line); w > maxW {
+			maxW = w
+		}
+	}
+
+	var lines []string
+	for _, line := range raw {
+		pad := maxW - lipgloss.Width(line)
+		if pad < 0 {
+			pad = 0
+		}
+		lines = append(lines, line+bgFill.Render(strings.Repeat(" ", pad)))
+	}
+
+	popup := dropdownPopupStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	content := lipgloss.JoinVertical(lipgloss.Center, header, popup)
+	return appStyle.Render(lipgloss.Place(m.termWidth, m.termHeight, lipgloss.Center, lipgloss.Center, content))
+}
@@ -21,6 +21,7 @@ type PathModel struct {
 	ChildDirs          []string
 	ChildDirsError     error
 	SelectedChildIndex int
+	Selection          string // child dir picked with the path/grid key, exposed to commands as {{selection}}
 	ShowHidden         bool
 	Searching          bool
 	Filter             string
@@ -191,6 +192,7 @@ func (pm *PathModel) UpdatePathMode(msg tea.KeyMsg, cfg config.Config) (navMode,
 		targetPath := pm.BuildPathFromComponents(pm.SelectedPathIndex)
 		if err := os.Chdir(targetPath); err == nil {
 			pm.CurrentPath, _ = os.Getwd()
+			pm.Selection = ""
 			return gridMode, func() tea.Msg { return pathChangedMsg{} }
 		}
 	case msg.String() == ".":
@@ -223,6 +225,7 @@ func (pm *PathModel) UpdateChildMode(msg tea.KeyMsg, cfg config.Config) (navMode
 			targetPath := filepath.Join(parentPath, pm.ChildDirs[pm.SelectedChildIndex])
 			if err := os.Chdir(targetPath); err == nil {
 				pm.CurrentPath, _ = os.Getwd()
+				pm.Selection = ""
 				return gridMode, func() tea.Msg { return pathChangedMsg{} }
 			}
 		case "backspace":
@@ -272,12 +275,18 @@ func (pm *PathModel) UpdateChildMode(msg tea.KeyMsg, cfg config.Config) (navMode
 			pm.SelectedChildIndex++
 		}
 	case IsPathGridMode(cfg.Keys, msg):
+		// Leaving with the path/grid key keeps the highlighted directory as the selection.
+		if pm.SelectedChildIndex < len(pm.ChildDirs) {
+			parentPath := pm.BuildPathFromComponents(pm.SelectedPathIndex)
+			pm.Selection = filepath.Join(parentPath, pm.ChildDirs[pm.SelectedChildIndex])
+		}
 		return gridMode, nil
 	case IsConfirm(cfg.Keys, msg):
 		parentPath := pm.BuildPathFromComponents(pm.SelectedPathIndex)
 		targetPath := filepath.Join(parentPath, pm.ChildDirs[pm.SelectedChildIndex])
 		if err := os.Chdir(targetPath); err == nil {
 			pm.CurrentPath, _ = os.Getwd()
+			pm.Selection = ""
 			return gridMode, func() tea.Msg { return pathChangedMsg{} }
 		}
 	case msg.String() == ".":
@@ -35,6 +35,9 @@ var (
 	offlineStyle              lipgloss.Style
 	cellStyle                 lipgloss.Style
 	selectedCellStyle         lipgloss.Style
+	cellSucceededStyle        lipgloss.Style
+	cellFailedStyle           lipgloss.Style
+	cellUnavailableStyle      lipgloss.Style
 	pathStyle                 lipgloss.Style
 	selectedPathStyle         lipgloss.Style
 	childDirStyle             lipgloss.Style
@@ -115,6 +118,16 @@ func applyThemeStyles(cfg config.Config) {
 		Bold(true).
 		Padding(0, 1)
 
+	// Cells whose last run succeeded or failed keep their text but take the status color on the border.
+	cellSucceededStyle = cellStyle.BorderForeground(lipgloss.Color(ui.StatusPositive))
+	cellFailedStyle = cellStyle.BorderForeground(lipgloss.Color(ui.StatusNegative))
+	// Cells whose requires is not met are greyed out.
+	cellUnavailableStyle = cellStyle.
+		BorderForeground(lipgloss.Color(ui.HelpFG)).
+		Foreground(lipgloss.Color(ui.HelpFG)).
+		Bold(false).
+		Faint(true)
+
 	pathStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color(ui.Path)).
 		Padding(0, 1)
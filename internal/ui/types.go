@@ -20,6 +20,11 @@ const (
 	dropdownMode
 	infoMode
 	lockedMode
+	paramMode
+	jobsMode
+	historyMode
+	confirmMode
+	outputMode
 )
 
 type (
@@ -56,6 +61,7 @@ type DetailMeta struct {
 type Config = config.Config
 type Command = config.Command
 type CommandItem = config.CommandItem
+type CommandParam = config.CommandParam
 type ProfileInfo = config.ProfileInfo
 type ProfileParseError = config.ProfileParseError
 type ConfigBundle = config.ConfigBundle
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +23,8 @@ func (m Model) Init() tea.Cmd {
 		m.spinner.Tick,
 		WatchConfigCmd(configDir),
 		lockCheckTick(),
+		// The first tick is immediate so requires.check results arrive without waiting.
+		func() tea.Msg { return probeTickMsg{} },
 	)
 }
 
@@ -38,6 +41,81 @@ func networkTick() tea.Cmd {
 	})
 }
 
+// probeTickMsg looks for status_command probes and requires.check commands that are due, and
+// writes out the notifications of background runs that finished meanwhile.
+type probeTickMsg struct{}
+
+// probeDoneMsg reports that a cell's probe finished; the result is kept by core.
+type probeDoneMsg struct{ cell string }
+
+// requirementCheckedMsg reports that a requires.check finished; the result is kept by core.
+type requirementCheckedMsg struct{ check string }
+
+// containerCheckedMsg reports whether the container of a cell about to run is up. mode is the
+// mode the check was started from; the execution is dropped if the user has moved on.
+type containerCheckedMsg struct {
+	name string
+	mode navMode
+	err  error
+}
+
+// containerStateMsg carries the container line of the explain overlay titled title.
+type containerStateMsg struct {
+	title string
+	value string
+}
+
+func probeTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return probeTickMsg{}
+	})
+}
+
+// dueProbes starts the status probes of the active profile that are due and not already running.
+// They run off the Update loop; core bounds how many run at once.
+func (m *Model) dueProbes() []tea.Cmd {
+	if m.probing == nil {
+		m.probing = make(map[string]bool)
+	}
+	profile, now := m.activeProfileName(), time.Now()
+	opts := core.RunOptions{Profile: profile}
+	var cmds []tea.Cmd
+	for _, c := range m.Config.Commands {
+		if m.probing[c.Name] || c.Unmet != "" || !core.ProbeDue(profile, c, now) {
+			continue
+		}
+		m.probing[c.Name] = true
+		cfg, c := m.Config, c
+		cmds = append(cmds, func() tea.Msg {
+			core.RunProbe(cfg, c, opts)
+			return probeDoneMsg{cell: c.Name}
+		})
+	}
+	return cmds
+}
+
+// dueRequirementChecks starts the requires.check commands of cfg (usually the active profile)
+// that have no fresh result and are not already running. Until one reports, its cells show as
+// checking.
+func (m *Model) dueRequirementChecks(cfg config.Config) []tea.Cmd {
+	if m.checking == nil {
+		m.checking = make(map[string]bool)
+	}
+	var cmds []tea.Cmd
+	for _, check := range core.PendingRequirementChecks(cfg) {
+		if m.checking[check] {
+			continue
+		}
+		m.checking[check] = true
+		check := check
+		cmds = append(cmds, func() tea.Msg {
+			core.RunRequirementCheck(cfg, check)
+			return requirementCheckedMsg{check: check}
+		})
+	}
+	return cmds
+}
+
 // lockCheckTick creates a command that checks for auto-lock every 30 seconds
 func lockCheckTick() tea.Cmd {
 	return tea.Tick(30*time.Second, func(time.Time) tea.Msg {
@@ -50,6 +128,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.termWidth = msg.Width
 		m.termHeight = msg.Height
+		m.resizeOutputViewer()
 		return m, nil
 
 	case pathChangedMsg:
@@ -118,6 +197,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateLockedMode(msg)
 		}
 
+		// The parameter prompt takes raw text, so it must see keys before any shortcut handling.
+		if m.mode == paramMode {
+			return m.updateParamMode(msg)
+		}
+
+		// The confirmation prompt may ask for the cell name to be typed back.
+		if m.mode == confirmMode {
+			return m.updateConfirmMode(msg)
+		}
+
+		// The jobs panel has its own single-key actions (r: re-run) that overlap global shortcuts.
+		if m.mode == jobsMode {
+			return m.updateJobsMode(msg)
+		}
+
+		// Same for the history browser, whose filter input takes raw text.
+		if m.mode == historyMode {
+			return m.updateHistoryMode(msg)
+		}
+
+		// The output viewer has a search input and scrolling keys of its own.
+		if m.mode == outputMode {
+			return m.updateOutputMode(msg)
+		}
+
 		// 1. Centralized Glassroot "Gatekeeper"
 		// Intercept restricted actions (Lock, Inventory, Path) early.
 		if m.GlassrootMode {
@@ -243,6 +347,75 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.profileStatusMessage = ""
 		return m, nil
 
+	case probeTickMsg:
+		cmds := append(m.dueRequirementChecks(m.Config), m.dueProbes()...)
+		if notices := core.TakeTerminalNotices(); len(notices) > 0 {
+			cmds = append(cmds, func() tea.Msg {
+				if err := core.WriteTerminalNotices(notices); err != nil {
+					log.Printf("could not write notifications: %v", err)
+				}
+				return nil
+			})
+		}
+		return m, tea.Batch(append(cmds, probeTick())...)
+
+	case requirementCheckedMsg:
+		delete(m.checking, msg.check)
+		m.applyRequirements()
+		return m, nil
+
+	case probeDoneMsg:
+		delete(m.probing, msg.cell)
+		return m, nil
+
+	case containerCheckedMsg:
+		if msg.name != m.checkingContainer || msg.mode != m.mode {
+			return m, nil
+		}
+		m.checkingContainer = ""
+		if msg.err != nil {
+			m.clearPending()
+			m.mode = gridMode
+			return m, m.setProfileStatus(msg.err.Error(), false)
+		}
+		m.containerChecked = msg.name
+		return m.startExecution(msg.name)
+
+	case containerStateMsg:
+		if m.mode == infoMode && m.activeDetail != nil && m.activeDetail.Title == msg.title {
+			for i := range m.activeDetail.Meta {
+				if m.activeDetail.Meta[i].Label == "Container" {
+					m.activeDetail.Meta[i].Value = msg.value
+				}
+			}
+		}
+		return m, nil
+
+	case itemsLoadedMsg:
+		if m.mode != dropdownMode || msg.cell != m.dropdownCell {
+			return m, nil
+		}
+		m.dropdownLoading = false
+		if msg.err != nil {
+			m.dropdownErr = msg.err.Error()
+			return m, nil
+		}
+		m.dropdownErr = ""
+		m.setGeneratedItems(msg.cell, msg.items)
+		if len(m.dropdownItems) == 0 {
+			m.dropdownErr = "items_command printed no items"
+		}
+		return m, nil
+
+	case jobsTickMsg:
+		// Keep ticking only while the panel is open
+		if m.mode != jobsMode {
+			return m, nil
+		}
+		m.refreshJobs()
+		m.refreshLastRuns()
+		return m, jobsTick()
+
 	case lockCheckMsg:
 		// Check if we should auto-lock
 		autoLockEnabled := m.Config.AutoLockEnabled == nil || *m.Config.AutoLockEnabled
@@ -253,6 +426,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m = m.enterLockedMode()
 			}
 		}
+		// Background jobs finish while the grid is idle; pick up their outcomes.
+		m.refreshLastRuns()
 		return m, lockCheckTick()
 
 	}
@@ -282,6 +457,16 @@ func (m Model) updateDropdownMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Close dropdown and return to grid mode
 		m.mode = gridMode
 		m.dropdownItems = nil
+		m.dropdownCell = ""
+		return m, nil
+	case msg.String() == "ctrl+r":
+		// Regenerate the items of a dynamic dropdown, bypassing the cache
+		for _, cmd := range m.Config.Commands {
+			if cmd.Name == m.dropdownCell && strings.TrimSpace(cmd.ItemsCommand) != "" && !m.dropdownLoading {
+				m.dropdownLoading, m.dropdownErr = true, ""
+				return m, loadItemsCmd(m.Config, cmd, m.RunOptions())
+			}
+		}
 		return m, nil
 	case Matches(m.Config.Keys, msg, "ctrl+c"):
 		m.Quitting = true
@@ -308,22 +493,16 @@ func (m Model) updateDropdownMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				title = fmt.Sprintf("%s: %s", parent, item.Name)
 			}
 
-			// Resolve execution mode and auto-close for item
-			autoClose := true
-			if item.AutoCloseExecution != nil {
-				autoClose = *item.AutoCloseExecution
-			}
-			debug := false
-			if item.DebugExecution != nil {
-				debug = *item.DebugExecution
-			}
-			execMode := "live"
-			if debug {
-				execMode = "debug"
+			// Items inherit cwd/env from their parent cell, so resolve them through the config.
+			spec := core.ResolveCommandSpec(nil, &item)
+			if p, it, ok := core.FindCommandIn(m.Config, m.dropdownCell, item.Name); ok && it != nil {
+				spec = core.ResolveCommandSpec(p, it)
 			}
 
-			cmdStr := ""
-			if strings.TrimSpace(item.Command) == "" {
+			cmdStr, keyLabel := "", "Command"
+			if len(item.Steps) > 0 {
+				cmdStr, keyLabel = stepsPreview(item.Steps), "Steps"
+			} else if strings.TrimSpace(item.Command) == "" {
 				cmdStr = "Error: no command configured"
 			} else {
 				cmdStr = item.Command
@@ -331,32 +510,97 @@ func (m Model) updateDropdownMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 			m.activeDetail = &DetailState{
 				Title:       title,
-				KeyLabel:    "Command",
+				KeyLabel:    keyLabel,
 				Value:       cmdStr,
 				Description: item.Description,
-				Meta: []DetailMeta{
-					{Label: "Exec", Value: execMode},
-					{Label: "Auto-close", Value: fmt.Sprintf("%v", autoClose)},
-					{Label: "CWD", Value: m.path.CurrentPath},
-				},
+				Meta:        withUnmet(item.Unmet, m.executionMeta(spec)),
 			}
 			m.mode = infoMode
-			return m, nil
+			return m, m.containerState(title, spec)
 		}
 		return m, nil
 	case IsConfirm(m.Config.Keys, msg):
 		// Execute the selected dropdown item
 		if m.dropdownSelectedIdx >= 0 && m.dropdownSelectedIdx < len(m.dropdownItems) {
 			selectedItem := m.dropdownItems[m.dropdownSelectedIdx]
-			m.Selected = selectedItem.Name
-			// Store the command to execute
-			// We need to create a temporary command entry for execution
-			return m, tea.Quit
+			if selectedItem.Unmet != "" {
+				return m, m.setProfileStatus("Unavailable: "+selectedItem.Unmet, false)
+			}
+			m.Parent = m.dropdownCell
+			return m.startExecution(selectedItem.Name)
 		}
 	}
 	return m, nil
 }
 
+// itemsLoadedMsg delivers the items generated by a cell's items_command.
+type itemsLoadedMsg struct {
+	cell  string
+	items []config.CommandItem
+	err   error
+}
+
+func loadItemsCmd(cfg config.Config, cmd config.Command, opts core.RunOptions) tea.Cmd {
+	return func() tea.Msg {
+		items, err := core.GenerateItems(cfg, cmd, opts)
+		return itemsLoadedMsg{cell: cmd.Name, items: items, err: err}
+	}
+}
+
+// openDropdown shows the items of a cell. Static items appear at once; items_command output
+// comes from the cache or is loaded in the background and appended when it arrives.
+func (m Model) openDropdown(cmd config.Command) (Model, tea.Cmd) {
+	m.mode = dropdownMode
+	m.dropdownRow = m.cursorRow
+	m.dropdownCol = m.cursorCol
+	m.dropdownCell = cmd.Name
+	m.dropdownItems = availableItems(cmd.Items)
+	m.dropdownSelectedIdx = 0
+	m.dropdownLoading, m.dropdownErr = false, ""
+	if strings.TrimSpace(cmd.ItemsCommand) == "" {
+		return m, nil
+	}
+	if items, ok := core.CachedItems(m.activeProfileName(), cmd); ok {
+		m.setGeneratedItems(cmd.Name, items)
+		return m, nil
+	}
+	m.dropdownLoading = true
+	return m, loadItemsCmd(m.Config, cmd, m.RunOptions())
+}
+
+// setGeneratedItems shows a cell's static items followed by the generated ones. The generated
+// items are also recorded on the cell in Config, so they resolve by name like configured items
+// when they are explained or run.
+func (m *Model) setGeneratedItems(cell string, generated []config.CommandItem) {
+	commands := config.CopyCommands(m.Config.Commands)
+	for i := range commands {
+		if commands[i].Name != cell {
+			continue
+		}
+		commands[i].Generated = generated
+		if cell == m.dropdownCell {
+			m.dropdownItems = slices.Concat(availableItems(commands[i].Items), generated)
+			if m.dropdownSelectedIdx >= len(m.dropdownItems) {
+				m.dropdownSelectedIdx = 0
+			}
+		}
+		break
+	}
+	m.Config.Commands = commands
+}
+
+// availableItems leaves out the items whose requires is unmet and asks to hide them.
+func availableItems(items []config.CommandItem) []config.CommandItem {
+	var shown []config.CommandItem
+	for _, it := range items {
+		if it.Unmet != "" && it.Requires != nil && it.Requires.Hide {
+			continue
+		}
+		shown = append(shown, it)
+	}
+	return shown
+}
+
 // copyToClipboardCmd copies text to clipboard using the best available method
 func copyToClipboardCmd(s string) tea.Cmd {
 	return func() tea.Msg {
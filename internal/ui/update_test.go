@@ -1,6 +1,11 @@
 package ui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -118,3 +123,533 @@ func TestUpdateInventoryMode_Navigation(t *testing.T) {
 		t.Errorf("Expected focusedList 0, got %d", m.inventory.focusedList)
 	}
 }
+
+func TestStartExecution_PromptsForParams(t *testing.T) {
+	m := createTestGridModel()
+	m.Config.Commands = []config.Command{
+		{Name: "A", Command: "echo {{msg}}", Params: []config.CommandParam{{Name: "msg", Default: "hi"}}},
+		{Name: "B", Command: "echo B"},
+	}
+
+	// A command without params quits straight away
+	tm, cmd := m.startExecution("B")
+	if got := tm.(Model); got.Selected != "B" || cmd == nil {
+		t.Fatalf("expected B to be selected for execution, got %q", got.Selected)
+	}
+
+	// A command with params opens the prompt instead
+	tm, _ = m.startExecution("A")
+	m = tm.(Model)
+	if m.mode != paramMode || m.Selected != "" {
+		t.Fatalf("expected paramMode without selection, got mode %v selected %q", m.mode, m.Selected)
+	}
+
+	// Type into the field and submit
+	tm, _ = m.updateParamMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	m = tm.(Model)
+	tm, cmd = m.updateParamMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(Model)
+	if m.Selected != "A" || cmd == nil {
+		t.Fatalf("expected A to be selected after submit, got %q", m.Selected)
+	}
+	if m.RunOptions().Params["msg"] != "hi!" {
+		t.Fatalf("expected msg=hi!, got %v", m.RunOptions().Params)
+	}
+
+	// Esc cancels back to the grid
+	m = createTestGridModel()
+	m.Config.Commands = []config.Command{{Name: "A", Command: "echo {{msg}}", Params: []config.CommandParam{{Name: "msg"}}}}
+	tm, _ = m.startExecution("A")
+	tm, _ = tm.(Model).updateParamMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := tm.(Model); got.mode != gridMode || got.Selected != "" {
+		t.Fatalf("expected cancel to return to grid, got mode %v selected %q", got.mode, got.Selected)
+	}
+}
+
+func TestUpdateHistoryMode_FilterReplayAndJump(t *testing.T) {
+	m := createTestGridModel()
+	m.Config.Commands = []config.Command{
+		{Name: "D", Items: []config.CommandItem{{Name: "D1", Command: "echo D1"}}},
+	}
+	m.history = historyModel{
+		all: []core.HistoryEntry{
+			{Cell: "D1", ExitCode: 1, Argv: []string{"bash", "-lc", "echo D1"}, Shell: "bash"},
+			{Cell: "A", ExitCode: 0, Argv: []string{"bash", "-lc", "echo A"}, Shell: "bash"},
+		},
+		returnMode: gridMode,
+	}
+	m.applyHistoryFilter()
+	m.mode = historyMode
+
+	// "/" starts editing; typed keys go to the query, not navigation
+	tm, _ := m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = tm.(Model)
+	tm, _ = m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("status:fail")})
+	m = tm.(Model)
+	tm, _ = m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(Model)
+	if m.history.editing || len(m.history.visible) != 1 || m.history.visible[0].Cell != "D1" {
+		t.Fatalf("expected only the failed entry, got %+v", m.history.visible)
+	}
+
+	// g jumps to the parent cell of a dropdown item
+	tm, _ = m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	jumped := tm.(Model)
+	if jumped.mode != gridMode || jumped.cursorRow != 1 || jumped.cursorCol != 1 {
+		t.Fatalf("expected cursor on D (1,1), got mode %v (%d,%d)", jumped.mode, jumped.cursorRow, jumped.cursorCol)
+	}
+
+	// enter hands the recorded entry to the runner
+	tm, cmd := m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(Model)
+	if cmd == nil || m.Selected != "D1" || m.RunOptions().Replay == nil || m.RunOptions().Replay.CommandLine() != "echo D1" {
+		t.Fatalf("expected replay of D1, got selected %q replay %+v", m.Selected, m.Replay)
+	}
+}
+
+func TestStartExecution_ReplayUsesRecordedProfile(t *testing.T) {
+	m := createTestGridModel()
+	m.profiles = []config.ProfileInfo{
+		{Name: "dev", Profile: config.ProfileFile{X: 2, Y: 2, Commands: []config.Command{{Name: "Deploy", Command: "true"}}}},
+		{Name: "ops", Profile: config.ProfileFile{X: 2, Y: 2, Commands: []config.Command{{Name: "Deploy", Command: "true", Confirm: "name"}}}},
+	}
+	m.Config.Commands = m.profiles[0].Profile.Commands
+
+	// The active profile's Deploy has no confirm, the recorded one asks for the name
+	m.Replay = &core.HistoryEntry{Profile: "ops", Cell: "Deploy", Argv: []string{"sh", "-c", "true"}}
+	tm, _ := m.startExecution("Deploy")
+	if got := tm.(Model); got.mode != confirmMode || got.confirm.level != core.ConfirmName {
+		t.Fatalf("expected the ops confirm prompt, got mode %v", got.mode)
+	}
+
+	// Once confirmed it runs with the ops config, not the active one
+	m.profiles[1].Profile.Timeout = "5s"
+	m.profiles[1].Profile.Env = map[string]string{"STAGE": "ops"}
+	confirmed := tm.(Model)
+	confirmed.confirmed = true
+	tm, _ = confirmed.startExecution("Deploy")
+	got := tm.(Model)
+	if cfg := got.RunConfig(); got.Selected != "Deploy" || cfg.Timeout != "5s" || cfg.Env["STAGE"] != "ops" {
+		t.Fatalf("expected the replay to run with the ops config, got selected %q config %+v", got.Selected, got.RunConfig())
+	}
+	if got.RunOptions().Profile != "ops" {
+		t.Errorf("expected the replay to be recorded under ops, got %q", got.RunOptions().Profile)
+	}
+
+	// A cell that no longer exists still asks
+	m.Replay = &core.HistoryEntry{Profile: "ops", Cell: "Wipe", Argv: []string{"sh", "-c", "true"}}
+	tm, _ = m.startExecution("Wipe")
+	if got := tm.(Model); got.mode != confirmMode || got.confirm.level != core.ConfirmYes {
+		t.Fatalf("expected a confirm prompt for a removed cell, got mode %v", got.mode)
+	}
+
+	// A profile that is no longer equipped is refused
+	m.Replay = &core.HistoryEntry{Profile: "gone", Cell: "Deploy", Argv: []string{"sh", "-c", "true"}}
+	tm, _ = m.startExecution("Deploy")
+	if got := tm.(Model); got.Selected != "" || got.mode != gridMode || got.Replay != nil {
+		t.Fatalf("expected the re-run from a missing profile to be refused, got mode %v selected %q", got.mode, got.Selected)
+	}
+
+	// Requirements of the recorded profile apply too
+	m.profiles[1].Profile.Commands[0].Requires = &config.Requirement{OS: []string{"plan9"}}
+	m.Replay = &core.HistoryEntry{Profile: "ops", Cell: "Deploy", Argv: []string{"sh", "-c", "true"}}
+	tm, _ = m.startExecution("Deploy")
+	if got := tm.(Model); got.Selected != "" || got.mode != gridMode || got.Replay != nil {
+		t.Fatalf("expected the re-run to be refused, got mode %v selected %q", got.mode, got.Selected)
+	}
+}
+
+func TestExecutionMeta_ShowsEffectiveCwdAndEnv(t *testing.T) {
+	m := createTestGridModel()
+	m.path.CurrentPath = t.TempDir()
+	if err := os.Mkdir(filepath.Join(m.path.CurrentPath, "web"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := m.executionMeta(core.CommandSpec{Cwd: "web", Env: map[string]string{"B": "2", "A": "1"}})
+	got := map[string]string{}
+	for _, entry := range meta {
+		got[entry.Label] = entry.Value
+	}
+	if got["CWD"] != filepath.Join(m.path.CurrentPath, "web") {
+		t.Fatalf("expected resolved cwd, got %q", got["CWD"])
+	}
+	if got["Env"] != "A=1 B=2" {
+		t.Fatalf("expected sorted env overrides, got %q", got["Env"])
+	}
+
+	meta = m.executionMeta(core.CommandSpec{Cwd: "missing"})
+	for _, entry := range meta {
+		if entry.Label == "CWD" && !strings.HasPrefix(entry.Value, "Error:") {
+			t.Fatalf("expected cwd error, got %q", entry.Value)
+		}
+	}
+}
+
+func TestStartExecution_Confirmation(t *testing.T) {
+	m := createTestGridModel()
+	m.Config.Commands = []config.Command{
+		{Name: "Update", Command: "apt upgrade", Confirm: "yes"},
+		{Name: "Wipe", Command: "rm -rf build", Confirm: "name"},
+		{Name: "Ls", Command: "ls"},
+	}
+
+	// y/N: anything but y cancels
+	tm, _ := m.startExecution("Update")
+	m2 := tm.(Model)
+	if m2.mode != confirmMode || m2.Selected != "" {
+		t.Fatalf("expected confirm prompt, got mode %v selected %q", m2.mode, m2.Selected)
+	}
+	tm, _ = m2.updateConfirmMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := tm.(Model); got.mode != gridMode || got.Selected != "" {
+		t.Fatalf("expected Enter to cancel, got mode %v selected %q", got.mode, got.Selected)
+	}
+	tm, cmd := m2.updateConfirmMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if got := tm.(Model); got.Selected != "Update" || cmd == nil {
+		t.Fatalf("expected y to run, got selected %q", got.Selected)
+	}
+
+	// name: only the exact name runs
+	tm, _ = m.startExecution("Wipe")
+	m2 = tm.(Model)
+	tm, _ = m2.updateConfirmMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Wip")})
+	tm, _ = tm.(Model).updateConfirmMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m2 = tm.(Model)
+	if m2.mode != confirmMode || m2.confirm.err == "" || m2.Selected != "" {
+		t.Fatalf("expected mismatch to keep the prompt open, got mode %v err %q", m2.mode, m2.confirm.err)
+	}
+	tm, _ = m2.updateConfirmMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	tm, _ = tm.(Model).updateConfirmMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := tm.(Model); got.Selected != "Wipe" {
+		t.Fatalf("expected typed name to run, got selected %q", got.Selected)
+	}
+
+	// Unguarded cells run straight away, unless glassroot forces a confirmation
+	if tm, _ = m.startExecution("Ls"); tm.(Model).Selected != "Ls" {
+		t.Fatal("expected unguarded cell to run without prompt")
+	}
+	m.GlassrootMode = true
+	m.Config.GlassrootConfirm = "yes"
+	if tm, _ = m.startExecution("Ls"); tm.(Model).mode != confirmMode {
+		t.Fatal("expected glassroot to force a confirmation")
+	}
+}
+
+func TestStartExecution_PicksHost(t *testing.T) {
+	m := createTestGridModel()
+	m.Config.Hosts = []string{"web1", "web2"}
+	m.Config.Commands = []config.Command{{Name: "Uptime", Command: "uptime"}}
+
+	tm, _ := m.startExecution("Uptime")
+	m2 := tm.(Model)
+	if m2.mode != paramMode || len(m2.paramForm.params) != 1 || m2.paramForm.params[0].Name != hostParamName {
+		t.Fatalf("expected host prompt, got mode %v", m2.mode)
+	}
+	tm, _ = m2.updateParamMode(tea.KeyMsg{Type: tea.KeyRight})
+	tm, _ = tm.(Model).updateParamMode(tea.KeyMsg{Type: tea.KeyEnter})
+	got := tm.(Model)
+	if got.Selected != "Uptime" || got.RunOptions().Host != "web2" {
+		t.Fatalf("expected Uptime on web2, got selected %q host %q", got.Selected, got.RunOptions().Host)
+	}
+	if _, ok := got.Params[hostParamName]; ok {
+		t.Error("host choice must not leak into the command parameters")
+	}
+
+	// A single host needs no prompt
+	m.Config.Hosts = []string{"web1"}
+	if tm, _ = m.startExecution("Uptime"); tm.(Model).Selected != "Uptime" {
+		t.Fatal("expected single-host profile to run without prompt")
+	}
+}
+
+func TestStartExecution_ContainerUnavailable(t *testing.T) {
+	m := createTestGridModel()
+	m.Config.Commands = []config.Command{{
+		Name:      "Shell",
+		Command:   "bash",
+		Container: &config.ContainerTarget{Name: "devbox", Runtime: "lxc"},
+	}}
+
+	// The runtime is asked off the update loop
+	tm, cmd := m.startExecution("Shell")
+	if got := tm.(Model); got.Selected != "" || cmd == nil {
+		t.Fatalf("expected a container check before running, got selected %q", got.Selected)
+	}
+	msg := cmd()
+	if checked, ok := msg.(containerCheckedMsg); !ok || checked.err == nil {
+		t.Fatalf("expected the check to fail for an unknown runtime, got %+v", msg)
+	}
+	tm, _ = tm.(Model).Update(msg)
+	if got := tm.(Model); got.Selected != "" || got.mode != gridMode || got.checkingContainer != "" {
+		t.Fatalf("expected the cell to stay in the grid, got selected %q mode %v", got.Selected, got.mode)
+	}
+
+	// A running container lets the execution go on; a stale result is ignored
+	tm, _ = m.startExecution("Shell")
+	running := tm.(Model)
+	if stale, _ := running.Update(containerCheckedMsg{name: "Shell", mode: dropdownMode}); stale.(Model).Selected != "" {
+		t.Fatal("expected a result from another mode to be ignored")
+	}
+	tm, _ = running.Update(containerCheckedMsg{name: "Shell", mode: gridMode})
+	if got := tm.(Model); got.Selected != "Shell" {
+		t.Fatalf("expected Shell to run once its container is up, got selected %q", got.Selected)
+	}
+
+	// The explain overlay fills in the container state when it arrives
+	m.Config.Commands[0].Container.User = "dev"
+	m.Config.Keys.Explain = "e"
+	m.grid[0][0] = "Shell"
+	tm, cmd = m.updateGridMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m = tm.(Model)
+	if m.mode != infoMode || cmd == nil || !strings.Contains(fmt.Sprint(m.activeDetail.Meta), "devbox as dev (checking…)") {
+		t.Fatalf("expected a pending container state, got %+v", m.activeDetail)
+	}
+	tm, _ = m.Update(cmd())
+	if meta := fmt.Sprint(tm.(Model).activeDetail.Meta); !strings.Contains(meta, "devbox as dev (Error: ") {
+		t.Errorf("expected the container error in the overlay, got %s", meta)
+	}
+}
+
+func TestUpdateHistoryMode_ShowsCapturedOutput(t *testing.T) {
+	capture := filepath.Join(t.TempDir(), "scan.log")
+	if err := os.WriteFile(capture, []byte("# drako capture: Scan\nhost up\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := createTestGridModel()
+	m.history = historyModel{
+		all: []core.HistoryEntry{
+			{Cell: "Scan", Mode: core.ModeDebug, Output: capture},
+			{Cell: "A"},
+		},
+		returnMode: gridMode,
+	}
+	m.applyHistoryFilter()
+	m.mode = historyMode
+
+	tm, _ := m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = tm.(Model)
+	if !m.history.showOutput || len(m.history.output) != 2 || m.history.output[1] != "host up" {
+		t.Fatalf("expected capture tail, got %q", m.history.output)
+	}
+
+	// Moving to an entry without a capture clears the output; esc closes it before leaving
+	tm, _ = m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyDown})
+	m = tm.(Model)
+	if m.history.output != nil {
+		t.Fatalf("expected no output for a live run, got %q", m.history.output)
+	}
+	tm, _ = m.updateHistoryMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := tm.(Model); got.mode != historyMode || got.history.showOutput {
+		t.Fatalf("expected esc to hide the output first, got mode %v", got.mode)
+	}
+}
+
+func TestOutputViewer_SearchAndClose(t *testing.T) {
+	capture := filepath.Join(t.TempDir(), "build.log")
+	content := "# drako capture: Build\n\x1b[32mok\x1b[0m pkg/a\n\x1b[31mFAIL\x1b[0m pkg/b\nprogress 10%\rprogress 100%\nfail: pkg/c\n"
+	if err := os.WriteFile(capture, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := createTestGridModel()
+	m.termWidth, m.termHeight = 100, 30
+	m = m.OpenOutput(core.HistoryEntry{Cell: "Build", Output: capture})
+	if m.mode != outputMode || len(m.output.lines) != 5 || m.output.lines[3] != "progress 100%" {
+		t.Fatalf("expected capture loaded with \\r collapsed, got mode %v lines %q", m.mode, m.output.lines)
+	}
+	if !strings.Contains(m.output.lines[1], "\x1b[32m") {
+		t.Error("expected ANSI colors to be preserved")
+	}
+
+	// e starts the search; matching ignores case and colors
+	tm, _ := m.updateOutputMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	tm, _ = tm.(Model).updateOutputMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("fail")})
+	tm, _ = tm.(Model).updateOutputMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(Model)
+	if m.output.searching || len(m.output.matches) != 2 || m.output.matches[0] != 2 {
+		t.Fatalf("expected two matches starting at line 2, got %v", m.output.matches)
+	}
+	tm, _ = m.updateOutputMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if got := tm.(Model); got.output.match != 1 {
+		t.Fatalf("expected n to move to the second match, got %d", got.output.match)
+	}
+
+	tm, _ = m.updateOutputMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := tm.(Model); got.mode != gridMode {
+		t.Fatalf("expected esc to return to the grid, got %v", got.mode)
+	}
+}
+
+func TestStartExecution_DryRun(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := createTestGridModel()
+	m.Config.DefaultShell = "sh"
+	m.Config.Keys.DryRun = "D"
+	m.Config.Commands = []config.Command{{Name: "Wipe", Command: "rm -rf build", Confirm: "yes"}}
+
+	tm, _ := m.updateGridMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	m = tm.(Model)
+	if !m.DryRun || !m.RunOptions().DryRun {
+		t.Fatal("expected D to turn dry run on")
+	}
+
+	// Guarded cells skip the confirmation: nothing runs anyway
+	tm, _ = m.startExecution("Wipe")
+	got := tm.(Model)
+	if got.Selected != "" || got.mode != infoMode || got.activeDetail == nil {
+		t.Fatalf("expected the dry-run report, got mode %v selected %q", got.mode, got.Selected)
+	}
+	if got.activeDetail.Value != "sh -c 'rm -rf build'" {
+		t.Errorf("unexpected exec line %q", got.activeDetail.Value)
+	}
+}
+
+func TestOpenDropdown_GeneratedItems(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := createTestGridModel()
+	m.Config.DefaultShell = "sh"
+	branches := config.Command{
+		Name:         "A",
+		Items:        []config.CommandItem{{Name: "fetch", Command: "git fetch"}},
+		ItemsCommand: "printf 'main\\nfeature x\\n'",
+		ItemCommand:  "git switch {{item}}",
+	}
+	broken := config.Command{Name: "B", ItemsCommand: "echo nope >&2; exit 3"}
+	m.Config.Commands = []config.Command{branches, broken}
+
+	m, cmd := m.openDropdown(branches)
+	if m.mode != dropdownMode || !m.dropdownLoading || cmd == nil || len(m.dropdownItems) != 1 {
+		t.Fatalf("expected the static item while loading, got %+v", m.dropdownItems)
+	}
+	tm, _ := m.Update(cmd())
+	m = tm.(Model)
+	if m.dropdownLoading || m.dropdownErr != "" || len(m.dropdownItems) != 3 {
+		t.Fatalf("expected static and generated items, got %+v (err %q)", m.dropdownItems, m.dropdownErr)
+	}
+	_, item, ok := core.FindCommandIn(m.Config, "A", "feature x")
+	if !ok || item.Command != "git switch 'feature x'" {
+		t.Fatalf("expected the generated item to resolve through its cell, got %+v", item)
+	}
+
+	// Picking it hands the cell along, so the item is not looked up by name alone
+	m.dropdownSelectedIdx = 2
+	tm, _ = m.updateDropdownMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := tm.(Model); got.Selected != "feature x" || got.RunOptions().Parent != "A" {
+		t.Fatalf("expected feature x of A to be selected, got %q (parent %q)", got.Selected, got.RunOptions().Parent)
+	}
+
+	// Reopening within items_cache does not run the command again
+	m.mode = gridMode
+	m, cmd = m.openDropdown(branches)
+	if m.dropdownLoading || cmd != nil || len(m.dropdownItems) != 3 {
+		t.Fatalf("expected cached items, got %+v", m.dropdownItems)
+	}
+
+	m.mode = gridMode
+	m, cmd = m.openDropdown(broken)
+	tm, _ = m.Update(cmd())
+	m = tm.(Model)
+	if !strings.Contains(m.dropdownErr, "nope") {
+		t.Fatalf("expected the command's stderr in the error, got %q", m.dropdownErr)
+	}
+	if !strings.Contains(m.renderDropdownPopup(), "✘") {
+		t.Error("expected the error to be rendered in the popup")
+	}
+}
+
+func TestUpdateGridMode_UnavailableCell(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := createTestGridModel()
+	m.Config.Keys.Explain = "?"
+	m.Config.Commands = []config.Command{
+		{Name: "A", Command: "docker ps", Unmet: "needs docker on PATH"},
+		{Name: "B", Items: []config.CommandItem{
+			{Name: "shown", Command: "true", Unmet: "only for macos"},
+			{Name: "hidden", Command: "true", Unmet: "only for macos", Requires: &config.Requirement{Hide: true}},
+			{Name: "ok", Command: "true"},
+		}},
+	}
+
+	tm, _ := m.updateGridMode(tea.KeyMsg{Type: tea.KeyEnter})
+	got := tm.(Model)
+	if got.mode != gridMode || got.Selected != "" || !strings.Contains(got.profileStatusMessage, "needs docker on PATH") {
+		t.Fatalf("expected the cell not to run and the reason in the status, got mode %v %q", got.mode, got.profileStatusMessage)
+	}
+
+	tm, _ = m.updateGridMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	got = tm.(Model)
+	if got.activeDetail == nil || got.activeDetail.Meta[0] != (DetailMeta{Label: "Unavailable", Value: "needs docker on PATH"}) {
+		t.Fatalf("expected explain to say why, got %+v", got.activeDetail)
+	}
+
+	m, _ = m.openDropdown(m.Config.Commands[1])
+	if len(m.dropdownItems) != 2 || m.dropdownItems[0].Name != "shown" {
+		t.Fatalf("expected the hidden item to be left out, got %+v", m.dropdownItems)
+	}
+	tm, _ = m.updateDropdownMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := tm.(Model); got.Selected != "" || got.mode != dropdownMode {
+		t.Fatalf("expected the unavailable item not to run, got mode %v", got.mode)
+	}
+}
+
+func TestRerunJob_GoesThroughConfirm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	background := true
+	m := createTestGridModel()
+	m.Config.DefaultShell = "sh"
+	m.Config.Commands = []config.Command{{Name: "Deploy", Command: "true", Background: &background}}
+
+	job, err := core.StartJob(m.Config, "Deploy", m.RunOptions())
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+
+	// The profile gained a confirm since the job ran; the re-run must honour it
+	m.Config.Commands[0].Confirm = "yes"
+	m.mode = jobsMode
+	tm, _ := m.rerunJob(job)
+	if got := tm.(Model); got.mode != confirmMode || got.confirm.returnMode != jobsMode {
+		t.Fatalf("expected the confirm prompt, got mode %v", got.mode)
+	}
+
+	// Unmet requirements refuse the re-run and keep the panel open
+	m.Config.Commands[0].Confirm = ""
+	m.Config.Commands[0].Unmet = "needs docker on PATH"
+	tm, _ = m.rerunJob(job)
+	if got := tm.(Model); got.mode != jobsMode || len(core.Jobs()) != 1 {
+		t.Fatalf("expected no new job, got mode %v and %d jobs", got.mode, len(core.Jobs()))
+	}
+}
+
+func TestRequirementChecks_RunOffTheUpdateLoop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := createTestGridModel()
+	m.applyConfig(config.Config{DefaultShell: "sh", Commands: []config.Command{
+		{Name: "VPN", Command: "wg-quick up wg0", Col: "a", Row: 0, Requires: &config.Requirement{Check: "exit 0 # ui vpn"}},
+	}})
+	if m.Config.Commands[0].Unmet != core.RequirementChecking {
+		t.Fatalf("expected the cell to be checking, got %q", m.Config.Commands[0].Unmet)
+	}
+
+	checks := m.dueRequirementChecks(m.Config)
+	if len(checks) != 1 || !m.checking["exit 0 # ui vpn"] {
+		t.Fatalf("expected one check to start, got %d", len(checks))
+	}
+	if again := m.dueRequirementChecks(m.Config); len(again) != 0 {
+		t.Fatal("expected a running check not to start twice")
+	}
+
+	tm, _ := m.Update(checks[0]())
+	got := tm.(Model)
+	if got.Config.Commands[0].Unmet != "" || len(got.checking) != 0 {
+		t.Fatalf("expected the cell to be available after its check, got %q", got.Config.Commands[0].Unmet)
+	}
+}
@@ -34,6 +34,26 @@ func (m Model) View() string {
 		return m.viewInfoMode()
 	}
 
+	if m.mode == paramMode {
+		return m.viewParamMode()
+	}
+
+	if m.mode == confirmMode {
+		return m.viewConfirmMode()
+	}
+
+	if m.mode == jobsMode {
+		return m.viewJobsMode()
+	}
+
+	if m.mode == historyMode {
+		return m.viewHistoryMode()
+	}
+
+	if m.mode == outputMode {
+		return m.viewOutputMode()
+	}
+
 	layout := CalculateLayout(m.termWidth, m.termHeight, m.Config)
 
 	header := ""
@@ -51,7 +71,7 @@ func (m Model) View() string {
 	case childMode:
 		helpText = "Child Mode | ↑/↓/ws: Select, Enter: cd, e: Search, q/Esc: Back"
 	default:
-		helpText = "Grid Mode | Enter: Select, e: Explain, Tab: Path, r: Start-Lock, i: Inventory"
+		helpText = "Grid Mode | Enter: Select, e: Explain, Tab: Path, r: Start-Lock, i: Inventory, J: Jobs, H: History, O: Output, D: Dry run"
 	}
 	help := helpStyle.Render(helpText)
 
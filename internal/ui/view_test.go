@@ -1,8 +1,10 @@
 package ui
 
 import (
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lucky7xz/drako/internal/config"
 	"github.com/lucky7xz/drako/internal/core"
@@ -71,6 +73,27 @@ func TestView_GridMode(t *testing.T) {
 	}
 }
 
+func TestView_GridShowsLastRun(t *testing.T) {
+	m := createTestModelForView(gridMode)
+	applyThemeStyles(m.Config)
+	now := time.Now()
+	m.lastRuns = map[string]core.HistoryEntry{
+		"Cmd2": {Cell: "Cmd2", ExitCode: 1, Start: now.Add(-6 * time.Minute), End: now.Add(-5 * time.Minute)},
+		"Cmd3": {Cell: "Cmd3", Start: now.Add(-3 * time.Hour), End: now.Add(-3 * time.Hour)},
+	}
+	output := m.renderGrid()
+
+	if !strings.Contains(output, "✘ 5m") {
+		t.Errorf("expected a failure badge for Cmd2. Got:\n%s", output)
+	}
+	if !strings.Contains(output, "✔ 3h") {
+		t.Errorf("expected a success badge for Cmd3. Got:\n%s", output)
+	}
+	if strings.Count(output, "✔")+strings.Count(output, "✘") != 2 {
+		t.Errorf("cells that never ran should have no badge. Got:\n%s", output)
+	}
+}
+
 func TestView_InventoryMode(t *testing.T) {
 	m := createTestModelForView(inventoryMode)
 	output := m.View()
@@ -103,3 +126,40 @@ func TestView_LockedMode(t *testing.T) {
 		t.Error("View output missing 'Pump' instruction")
 	}
 }
+
+func TestView_GridShowsProbeStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := createTestModelForView(gridMode)
+	applyThemeStyles(m.Config)
+	m.Config.DefaultShell = "sh"
+	m.Config.Commands = []config.Command{
+		{Name: "Cmd1", StatusCommand: "echo up"},
+		{Name: "Cmd4", StatusCommand: "exit 3"},
+	}
+
+	if !strings.Contains(m.renderGrid(), "…") {
+		t.Error("expected a placeholder before the first probe")
+	}
+	cmds := m.dueProbes()
+	if len(cmds) != 2 || !m.probing["Cmd1"] {
+		t.Fatalf("expected both probes to start, got %d", len(cmds))
+	}
+	if more := m.dueProbes(); len(more) != 0 {
+		t.Fatal("expected running probes not to start twice")
+	}
+	for _, cmd := range cmds {
+		tm, _ := m.Update(cmd())
+		m = tm.(Model)
+	}
+	if len(m.probing) != 0 {
+		t.Fatalf("expected no probes running, got %v", m.probing)
+	}
+
+	output := m.renderGrid()
+	if !strings.Contains(output, "● up") || !strings.Contains(output, "● exit 3") {
+		t.Errorf("expected the probe results on the cells. Got:\n%s", output)
+	}
+}